@@ -0,0 +1,103 @@
+package gonagios
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Acknowledgement acknowledges a host or service problem, so notifications stop until the state changes
+// The problem is a service problem when ServiceDescription is set
+type Acknowledgement struct {
+	HostName           string
+	ServiceDescription string
+	Author             string
+	Comment            string
+	// A sticky acknowledgement lasts until the object recovers, instead of until its state changes
+	Sticky bool
+	// Notify sends an acknowledgement notification to the contacts
+	Notify bool
+	// A persistent comment is kept when Nagios restarts
+	Persistent bool
+}
+
+// command builds the external command that acknowledges the problem
+func (ack *Acknowledgement) command() (string, error) {
+	check := &validator{}
+
+	check.required("host_name", ack.HostName)
+	check.required("author", ack.Author)
+	check.required("comment", ack.Comment)
+
+	fields := map[string]string{
+		"host_name":           ack.HostName,
+		"service_description": ack.ServiceDescription,
+		"author":              ack.Author,
+		"comment":             ack.Comment,
+	}
+
+	for field, value := range fields {
+		check.commandArgument(field, value)
+	}
+
+	if err := check.result("acknowledgement", ack.HostName); err != nil {
+		return "", err
+	}
+
+	sticky := "0"
+	if ack.Sticky {
+		sticky = "2"
+	}
+
+	options := []string{sticky, convertBoolToIntToString(ack.Notify), convertBoolToIntToString(ack.Persistent), ack.Author, ack.Comment}
+
+	if ack.ServiceDescription != "" {
+		return "ACKNOWLEDGE_SVC_PROBLEM;" + ack.HostName + ";" + ack.ServiceDescription + ";" + strings.Join(options, ";"), nil
+	}
+
+	return "ACKNOWLEDGE_HOST_PROBLEM;" + ack.HostName + ";" + strings.Join(options, ";"), nil
+}
+
+// Acknowledge acknowledges a host or service problem
+// The acknowledgement is checked before it is sent, every problem is returned at once in a *ValidationError
+func (client *Client) Acknowledge(ack *Acknowledgement) error {
+	command, err := ack.command()
+
+	if err != nil {
+		return err
+	}
+
+	return client.coreCommand(command)
+}
+
+// RemoveAcknowledgement removes the acknowledgement of a host problem, or of a service problem when
+// serviceDescription is set
+func (client *Client) RemoveAcknowledgement(hostName, serviceDescription string) error {
+	check := &validator{}
+
+	check.required("host_name", hostName)
+	check.commandArgument("host_name", hostName)
+	check.commandArgument("service_description", serviceDescription)
+
+	if err := check.result("acknowledgement", hostName); err != nil {
+		return err
+	}
+
+	if serviceDescription != "" {
+		return client.coreCommand("REMOVE_SVC_ACKNOWLEDGEMENT;" + hostName + ";" + serviceDescription)
+	}
+
+	return client.coreCommand("REMOVE_HOST_ACKNOWLEDGEMENT;" + hostName)
+}
+
+// coreCommand sends an external command to the Nagios core
+func (client *Client) coreCommand(command string) error {
+	nagiosURL := client.buildURL("system", "corecommand", http.MethodPost)
+
+	data := &url.Values{}
+	data.Set("cmd", command)
+
+	_, err := client.post(data, nagiosURL)
+
+	return err
+}
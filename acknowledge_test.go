@@ -0,0 +1,47 @@
+package gonagios
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcknowledge_commands(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+
+	assert.NoError(t, client.Acknowledge(&Acknowledgement{HostName: "web01", Author: "oncall", Comment: "looking", Sticky: true, Notify: true}))
+	assert.NoError(t, client.Acknowledge(&Acknowledgement{HostName: "web01", ServiceDescription: "HTTP", Author: "oncall", Comment: "known", Persistent: true}))
+	assert.NoError(t, client.RemoveAcknowledgement("web01", ""))
+	assert.NoError(t, client.RemoveAcknowledgement("web01", "HTTP"))
+
+	assert.Equal(t, []string{
+		"ACKNOWLEDGE_HOST_PROBLEM;web01;2;1;0;oncall;looking",
+		"ACKNOWLEDGE_SVC_PROBLEM;web01;HTTP;0;0;1;oncall;known",
+		"REMOVE_HOST_ACKNOWLEDGEMENT;web01",
+		"REMOVE_SVC_ACKNOWLEDGEMENT;web01;HTTP",
+	}, server.Commands())
+}
+
+func TestAcknowledge_validation(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+
+	err := client.Acknowledge(&Acknowledgement{HostName: "web01", Author: "oncall", Comment: "a;REMOVE_HOST_ACKNOWLEDGEMENT"})
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Contains(t, err.Error(), "comment: must not contain ';'")
+
+	err = client.Acknowledge(&Acknowledgement{HostName: "web01"})
+	assert.Contains(t, err.Error(), "author: is required")
+
+	err = client.RemoveAcknowledgement("web01", "HTTP\nREMOVE")
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	assert.Empty(t, server.Commands())
+}
@@ -2,38 +2,161 @@ package gonagios
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
 )
 
 // ResponseCode contains the list of available responses from Nagios
 // It is used grab messages from Nagios to determine if the action
 // was successful or an error occurred
 type ResponseCode struct {
-	ResponseSuccess string `json:"success"`
-	ReponseError    string `json:"error"`
+	ResponseSuccess string      `json:"success"`
+	ReponseError    string      `json:"error"`
+	Missing         []string    `json:"missing,omitempty"`
+	Messages        apiMessages `json:"messages,omitempty"`
 }
 
-// readAPIResponse reads the body of the HTTP response sent from the Nagios XI API
+// apiMessages holds the 'messages' returned by Nagios XI alongside an error
+// Depending on the endpoint XI sends a single string, an array or an object keyed by field name,
+// so we flatten all of them into a list of strings
+type apiMessages []string
+
+// UnmarshalJSON accepts a string, an array of strings or an object of strings
+func (messages *apiMessages) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*messages = apiMessages{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*messages = list
+		return nil
+	}
+
+	var keyed map[string]interface{}
+	if err := json.Unmarshal(data, &keyed); err != nil {
+		return err
+	}
+
+	// Keys are sorted so the messages, and the errors built from them, are the same every time
+	keys := make([]string, 0, len(keyed))
+	for key := range keyed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if text, ok := keyed[key].(string); ok {
+			*messages = append(*messages, key+": "+text)
+		}
+	}
+
+	return nil
+}
+
+// flexString holds a value that XI returns as either a JSON string or a JSON number
+type flexString string
+
+// UnmarshalJSON accepts a string, a number, a boolean or null
+func (value *flexString) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*value = flexString(text)
+		return nil
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch raw := raw.(type) {
+	case nil:
+		*value = ""
+	case bool:
+		*value = flexString(convertBoolToIntToString(raw))
+	case float64:
+		*value = flexString(strconv.FormatFloat(raw, 'f', -1, 64))
+	default:
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(value).Elem()}
+	}
+
+	return nil
+}
+
+// maxResponseSize is the largest response body we are willing to read from Nagios
+// Listing every object of a large instance is a few MB, anything beyond that is not a valid API response
+const maxResponseSize = 64 << 20
+
+// parseAPIResponse reads the body of the HTTP response sent from the Nagios XI API
 // Nagios does not return errors in a way that golang will catch them in the err variable
 // We need to enhance the io.ReadAll function with determing if Nagios returns a response of
-// 'success' or 'error'
-func parseAPIResponse(reader io.Reader) ([]byte, error) {
-	var errorCode error
-	body, _ := ioutil.ReadAll(reader)
+// 'success' or 'error', and check the HTTP status code which XI sets on some failures
+// When the API key is wrong or XI is being upgraded we get a HTML login page or PHP output instead of JSON,
+// which is reported as ErrUnexpectedResponse with a snippet of the body
+func parseAPIResponse(response *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxResponseSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > maxResponseSize {
+		return nil, unexpectedResponseError(response, body, "response exceeds "+strconv.Itoa(maxResponseSize)+" bytes")
+	}
+
+	if !looksLikeJSON(body) {
+		return nil, unexpectedResponseError(response, body, "expected JSON but got '"+response.Header.Get("Content-Type")+"'")
+	}
 
 	responseCode := &ResponseCode{}
 
-	err := json.Unmarshal(body, &responseCode)
+	// A HTTP GET returns the requested objects, which is usually a JSON array. Only a JSON object carries a
+	// response code, while a POST, PUT or DELETE must always return one
+	if response.Request.Method != http.MethodGet || isJSONObject(body) {
+		err = json.Unmarshal(body, &responseCode)
 
-	if err != nil {
-		return nil, err
+		if err != nil && response.Request.Method != http.MethodGet {
+			return nil, unexpectedResponseError(response, body, err.Error())
+		}
 	}
 
-	if responseCode.ReponseError != "" {
-		errorCode = errors.New(responseCode.ReponseError)
+	if responseCode.ReponseError != "" || response.StatusCode >= http.StatusBadRequest {
+		return body, newAPIError(response, responseCode)
+	}
+
+	return body, nil
+}
+
+// looksLikeJSON returns true if the body starts with a JSON object or array
+// XI does not reliably set the Content-Type header on API responses, so we sniff the body instead
+func looksLikeJSON(body []byte) bool {
+	first := firstNonSpace(body)
+
+	return first == '{' || first == '['
+}
+
+// isJSONObject returns true if the body holds a JSON object rather than an array or scalar
+func isJSONObject(body []byte) bool {
+	return firstNonSpace(body) == '{'
+}
+
+// firstNonSpace returns the first byte of the body that is not whitespace, or zero if there is none
+func firstNonSpace(body []byte) byte {
+	for _, b := range body {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return b
 	}
 
-	return body, errorCode
+	return 0
 }
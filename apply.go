@@ -0,0 +1,279 @@
+package gonagios
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrApplyConfigFailed is returned when Nagios rejects the new configuration, usually because verification failed
+var ErrApplyConfigFailed = errors.New("apply config failed")
+
+// ErrApplyConfigUnconfirmed is returned by ApplyConfigAndWait when XI did not return a command ID and the core
+// kept running with its old program start time until the wait ended, which usually means verification failed
+var ErrApplyConfigUnconfirmed = errors.New("apply config not confirmed")
+
+// DefaultApplyConfigTimeout is how long ApplyConfigAndWait waits when its context has no deadline
+const DefaultApplyConfigTimeout = 5 * time.Minute
+
+// applyConfigPollInterval is how often ApplyConfigAndWait checks if the core has restarted
+var applyConfigPollInterval = 2 * time.Second
+
+// applyConfigTimeout is DefaultApplyConfigTimeout, shortened by the tests
+var applyConfigTimeout = DefaultApplyConfigTimeout
+
+// programTimeLayout is the format XI uses for timestamps in system/status
+const programTimeLayout = "2006-01-02 15:04:05"
+
+// SystemStatus contains the state of the Nagios core process as reported by system/status
+type SystemStatus struct {
+	InstanceID         string `json:"instance_id"`
+	InstanceName       string `json:"instance_name"`
+	StatusUpdateTime   string `json:"status_update_time"`
+	ProgramStartTime   string `json:"program_start_time"`
+	IsCurrentlyRunning string `json:"is_currently_running"`
+	ProcessID          string `json:"process_id"`
+}
+
+// UnmarshalJSON decodes the system status, accepting numbers where XI sometimes sends them instead of strings
+func (status *SystemStatus) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		InstanceID         flexString `json:"instance_id"`
+		InstanceName       flexString `json:"instance_name"`
+		StatusUpdateTime   flexString `json:"status_update_time"`
+		ProgramStartTime   flexString `json:"program_start_time"`
+		IsCurrentlyRunning flexString `json:"is_currently_running"`
+		ProcessID          flexString `json:"process_id"`
+	}{}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*status = SystemStatus{
+		InstanceID:         string(raw.InstanceID),
+		InstanceName:       string(raw.InstanceName),
+		StatusUpdateTime:   string(raw.StatusUpdateTime),
+		ProgramStartTime:   string(raw.ProgramStartTime),
+		IsCurrentlyRunning: string(raw.IsCurrentlyRunning),
+		ProcessID:          string(raw.ProcessID),
+	}
+
+	return nil
+}
+
+// ApplyResult describes a completed apply config
+type ApplyResult struct {
+	CommandID        int
+	ProgramStartTime time.Time
+	Duration         time.Duration
+}
+
+// ApplyConfigError is returned when XI reports that the apply config command failed
+// Output holds what the command printed, which includes the output of the configuration verification
+type ApplyConfigError struct {
+	CommandID  int
+	ResultCode int
+	Output     string
+}
+
+// applyCommand is the status of a command queued on the XI backend, as reported by system/command
+type applyCommand struct {
+	CommandID  flexString `json:"command_id"`
+	StatusCode flexString `json:"status_code"`
+	ResultCode flexString `json:"result_code"`
+	Result     flexString `json:"result"`
+}
+
+// commandStatusCompleted is the status_code of a command the XI backend finished running
+const commandStatusCompleted = "2"
+
+// GetSystemStatus retrieves the state of the Nagios core process
+func (client *Client) GetSystemStatus() (*SystemStatus, error) {
+	return client.getSystemStatus(context.Background())
+}
+
+// getSystemStatus retrieves the state of the Nagios core process, cancelled along with ctx
+func (client *Client) getSystemStatus(ctx context.Context) (*SystemStatus, error) {
+	nagiosURL := client.buildURL("system", "status", http.MethodGet)
+
+	body, err := client.getContext(ctx, "", nagiosURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	status := &SystemStatus{}
+
+	err = json.Unmarshal(body, status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Running returns true if the core process is up
+func (status *SystemStatus) Running() bool {
+	return status.IsCurrentlyRunning == "1"
+}
+
+// ApplyConfig applies the configuration without waiting for the Nagios core to restart, see ApplyConfigAndWait
+func (client *Client) ApplyConfig() error {
+	return client.applyConfig()
+}
+
+// ApplyConfigAndWait applies the configuration and waits until the Nagios core has restarted with it
+// The core is considered restarted once its program start time has advanced. Use a context with a deadline
+// to bound how long to wait, when it has none the wait ends after DefaultApplyConfigTimeout
+// If XI reports that the command failed, an *ApplyConfigError is returned straight away. Older XI versions do
+// not return the ID of the command, so a failed verification cannot be told apart from a slow restart until
+// the wait ends: the error then wraps ErrApplyConfigUnconfirmed if the core never stopped, as well as the
+// context error
+func (client *Client) ApplyConfigAndWait(ctx context.Context) (*ApplyResult, error) {
+	start := time.Now()
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, applyConfigTimeout)
+		defer cancel()
+	}
+
+	before, err := client.getSystemStatus(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := client.applyConfigContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Newer XI versions return the ID of the queued command, which lets us check its result
+	response := struct {
+		CommandID flexString `json:"command_id"`
+	}{}
+
+	json.Unmarshal(body, &response)
+
+	result := &ApplyResult{}
+	result.CommandID, _ = strconv.Atoi(string(response.CommandID))
+
+	ticker := time.NewTicker(applyConfigPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+
+	// stopped is set once the core is seen restarting, which a configuration that failed verification never does
+	stopped := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			if result.CommandID == 0 && !stopped {
+				return nil, fmt.Errorf("%w: the Nagios core kept running with its old configuration, check that it verifies: %w",
+					ErrApplyConfigUnconfirmed, ctx.Err())
+			}
+			if lastErr != nil {
+				return nil, fmt.Errorf("waiting for Nagios core to restart: %w (last error: %v)", ctx.Err(), lastErr)
+			}
+			return nil, fmt.Errorf("waiting for Nagios core to restart: %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		if result.CommandID > 0 {
+			command, err := client.getCommand(ctx, result.CommandID)
+
+			if err == nil && command.StatusCode == commandStatusCompleted && command.ResultCode != "0" && command.ResultCode != "" {
+				resultCode, _ := strconv.Atoi(string(command.ResultCode))
+
+				return nil, &ApplyConfigError{
+					CommandID:  result.CommandID,
+					ResultCode: resultCode,
+					Output:     string(command.Result),
+				}
+			}
+		}
+
+		// The API is unavailable for a moment while the core restarts, so errors here are expected
+		status, err := client.getSystemStatus(ctx)
+
+		if err != nil {
+			lastErr = err
+			// An error caused by the end of the wait says nothing about the core
+			if ctx.Err() == nil {
+				stopped = true
+			}
+			continue
+		}
+
+		if !status.Running() {
+			stopped = true
+		}
+
+		if status.Running() && status.ProgramStartTime != before.ProgramStartTime {
+			result.ProgramStartTime, _ = time.ParseInLocation(programTimeLayout, status.ProgramStartTime, time.Local)
+			result.Duration = time.Since(start)
+
+			return result, nil
+		}
+	}
+}
+
+// getCommand retrieves the status of a command queued on the XI backend
+func (client *Client) getCommand(ctx context.Context, commandID int) (*applyCommand, error) {
+	nagiosURL := client.buildURL("system", "command", http.MethodGet)
+
+	nagiosURL = addQueryParams(nagiosURL, url.Values{"command_id": {strconv.Itoa(commandID)}})
+
+	body, err := client.getContext(ctx, "", nagiosURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// XI returns a list of commands matching the filter, or the command itself
+	var commands []applyCommand
+
+	if isJSONObject(body) {
+		command := applyCommand{}
+		err = json.Unmarshal(body, &command)
+		commands = append(commands, command)
+	} else {
+		err = json.Unmarshal(body, &commands)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commands) == 0 {
+		return nil, notFoundError("command", strconv.Itoa(commandID))
+	}
+
+	return &commands[0], nil
+}
+
+// Error returns the result code and output of the failed command
+func (applyError *ApplyConfigError) Error() string {
+	message := "nagios: apply config command " + strconv.Itoa(applyError.CommandID) + " failed with result code " + strconv.Itoa(applyError.ResultCode)
+
+	if applyError.Output != "" {
+		message += ": " + applyError.Output
+	}
+
+	return message
+}
+
+// Unwrap returns ErrApplyConfigFailed
+func (applyError *ApplyConfigError) Unwrap() error {
+	return ErrApplyConfigFailed
+}
@@ -0,0 +1,168 @@
+package gonagios
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyConfigAndWait_restarted(t *testing.T) {
+	defer func(interval time.Duration) { applyConfigPollInterval = interval }(applyConfigPollInterval)
+	applyConfigPollInterval = time.Millisecond
+
+	var applied, polls int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/applyconfig"):
+			atomic.StoreInt32(&applied, 1)
+			w.Write([]byte(`{"success":"Apply config command has been sent to the backend.","command_id":12}`))
+		case strings.HasSuffix(r.URL.Path, "/command"):
+			w.Write([]byte(`[{"command_id":"12","status_code":"1","result_code":"0"}]`))
+		case atomic.LoadInt32(&applied) == 0:
+			w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"1"}`))
+		case atomic.AddInt32(&polls, 1) < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"program_start_time":"2019-10-01 10:05:00","is_currently_running":1}`))
+		}
+	})
+	defer server.Close()
+
+	client.RetryPolicy = nil
+
+	result, err := client.ApplyConfigAndWait(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 12, result.CommandID)
+	assert.Equal(t, 5, result.ProgramStartTime.Minute())
+}
+
+func TestApplyConfigAndWait_verificationFailed(t *testing.T) {
+	defer func(interval time.Duration) { applyConfigPollInterval = interval }(applyConfigPollInterval)
+	applyConfigPollInterval = time.Millisecond
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/applyconfig"):
+			w.Write([]byte(`{"success":"Apply config command has been sent to the backend.","command_id":7}`))
+		case strings.HasSuffix(r.URL.Path, "/command"):
+			w.Write([]byte(`{"command_id":7,"status_code":2,"result_code":1,"result":"Error: Could not find any host matching 'web1'"}`))
+		default:
+			w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"1"}`))
+		}
+	})
+	defer server.Close()
+
+	_, err := client.ApplyConfigAndWait(context.Background())
+
+	var applyError *ApplyConfigError
+	assert.True(t, errors.As(err, &applyError))
+	assert.True(t, errors.Is(err, ErrApplyConfigFailed))
+	assert.Equal(t, 1, applyError.ResultCode)
+	assert.Contains(t, err.Error(), "web1")
+}
+
+func TestApplyConfigAndWait_timeout(t *testing.T) {
+	defer func(interval time.Duration) { applyConfigPollInterval = interval }(applyConfigPollInterval)
+	applyConfigPollInterval = time.Millisecond
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/applyconfig") {
+			w.Write([]byte(`{"success":"ok"}`))
+			return
+		}
+		w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"1"}`))
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ApplyConfigAndWait(ctx)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestApplyConfigAndWait_defaultTimeout(t *testing.T) {
+	defer func(interval time.Duration) { applyConfigPollInterval = interval }(applyConfigPollInterval)
+	defer func(timeout time.Duration) { applyConfigTimeout = timeout }(applyConfigTimeout)
+	applyConfigPollInterval = time.Millisecond
+	applyConfigTimeout = 20 * time.Millisecond
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/applyconfig") {
+			w.Write([]byte(`{"success":"ok"}`))
+			return
+		}
+		w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"1"}`))
+	})
+	defer server.Close()
+
+	_, err := client.ApplyConfigAndWait(context.Background())
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestApplyConfigAndWait_unconfirmed(t *testing.T) {
+	defer func(interval time.Duration) { applyConfigPollInterval = interval }(applyConfigPollInterval)
+	applyConfigPollInterval = time.Millisecond
+
+	var applied int32
+
+	// Without a command ID a failed verification leaves the core running with its old start time
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/applyconfig") {
+			atomic.StoreInt32(&applied, 1)
+			w.Write([]byte(`{"success":"Apply config command has been sent to the backend."}`))
+			return
+		}
+		w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"1"}`))
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ApplyConfigAndWait(ctx)
+
+	assert.True(t, errors.Is(err, ErrApplyConfigUnconfirmed))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&applied))
+}
+
+func TestApplyConfigAndWait_slowRestart(t *testing.T) {
+	defer func(interval time.Duration) { applyConfigPollInterval = interval }(applyConfigPollInterval)
+	applyConfigPollInterval = time.Millisecond
+
+	var applied int32
+
+	// The core stopped, so it is restarting and the wait only ran out of time
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/applyconfig"):
+			atomic.StoreInt32(&applied, 1)
+			w.Write([]byte(`{"success":"Apply config command has been sent to the backend."}`))
+		case atomic.LoadInt32(&applied) == 0:
+			w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"1"}`))
+		default:
+			w.Write([]byte(`{"program_start_time":"2019-10-01 10:00:00","is_currently_running":"0"}`))
+		}
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ApplyConfigAndWait(ctx)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, errors.Is(err, ErrApplyConfigUnconfirmed))
+}
@@ -0,0 +1,152 @@
+package gonagios
+
+import (
+	"sync"
+)
+
+// DefaultBulkConcurrency is how many requests the bulk operations send at once when Client.BulkConcurrency is not set
+const DefaultBulkConcurrency = 8
+
+// BulkReport holds the outcome of every write made by a bulk operation, in the order the objects were given
+type BulkReport struct {
+	Results []WriteResult
+}
+
+// Succeeded returns the writes that succeeded
+func (report *BulkReport) Succeeded() []WriteResult {
+	var results []WriteResult
+
+	for _, result := range report.Results {
+		if result.Err == nil {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// Failed returns the writes that failed
+func (report *BulkReport) Failed() []WriteResult {
+	var results []WriteResult
+
+	for _, result := range report.Results {
+		if result.Err != nil {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// BulkCreateHosts creates many hosts at once and applies the configuration a single time
+// Every host is attempted even when some fail. The error is a *CommitError listing every failed write
+func (client *Client) BulkCreateHosts(hosts []*Host) (*BulkReport, error) {
+	return client.bulk(OperationCreate, len(hosts), func(i int) (string, string) {
+		return objectType, hosts[i].HostName
+	}, func(i int) error {
+		_, err := client.createHost(hosts[i], false)
+		return err
+	})
+}
+
+// BulkUpdateHosts updates many existing hosts at once, each identified by its HostName, and applies the
+// configuration a single time. The error is a *CommitError listing every failed write
+func (client *Client) BulkUpdateHosts(hosts []*Host) (*BulkReport, error) {
+	return client.bulk(OperationUpdate, len(hosts), func(i int) (string, string) {
+		return objectType, hosts[i].HostName
+	}, func(i int) error {
+		return client.updateHost(hosts[i], hosts[i].HostName)
+	})
+}
+
+// BulkDeleteHosts deletes many hosts at once and applies the configuration a single time
+// The error is a *CommitError listing every failed write
+func (client *Client) BulkDeleteHosts(names []string) (*BulkReport, error) {
+	return client.bulk(OperationDelete, len(names), func(i int) (string, string) {
+		return objectType, names[i]
+	}, func(i int) error {
+		_, err := client.deleteHost(names[i])
+		return err
+	})
+}
+
+// BulkCreateObjects creates many objects of any type at once and applies the configuration a single time
+func (client *Client) BulkCreateObjects(objects []ConfigObject) (*BulkReport, error) {
+	return client.bulk(OperationCreate, len(objects), configObjects(objects).describe, func(i int) error {
+		return client.createObject(&objects[i], false)
+	})
+}
+
+// BulkUpdateObjects sends every attribute of many existing objects of any type at once and applies the
+// configuration a single time
+func (client *Client) BulkUpdateObjects(objects []ConfigObject) (*BulkReport, error) {
+	return client.bulk(OperationUpdate, len(objects), configObjects(objects).describe, func(i int) error {
+		return client.replaceObject(&objects[i])
+	})
+}
+
+// BulkDeleteObjects deletes many objects of any type at once and applies the configuration a single time
+func (client *Client) BulkDeleteObjects(objects []ConfigObject) (*BulkReport, error) {
+	return client.bulk(OperationDelete, len(objects), configObjects(objects).describe, func(i int) error {
+		return client.deleteObject(&objects[i])
+	})
+}
+
+// configObjects lets the generic bulk operations describe their objects
+type configObjects []ConfigObject
+
+// describe returns the type and name of an object for its WriteResult
+func (objects configObjects) describe(i int) (string, string) {
+	return string(objects[i].Type), objects[i].Name()
+}
+
+// bulk runs send for every object with the client's BulkConcurrency, in a ConfigSession so the
+// configuration is applied once at the end, and records the results in the order of the objects
+func (client *Client) bulk(operation Operation, count int, describe func(i int) (string, string), send func(i int) error) (*BulkReport, error) {
+	session := client.NewConfigSession()
+	report := &BulkReport{Results: make([]WriteResult, count)}
+
+	workers := client.BulkConcurrency
+	if workers <= 0 {
+		workers = DefaultBulkConcurrency
+	}
+
+	if workers > count {
+		workers = count
+	}
+
+	jobs := make(chan int)
+
+	var wait sync.WaitGroup
+	wait.Add(workers)
+
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			defer wait.Done()
+
+			for i := range jobs {
+				objectType, name := describe(i)
+
+				err := session.write(operation, objectType, name, func() error {
+					return send(i)
+				})
+
+				report.Results[i] = WriteResult{
+					Operation:  operation,
+					ObjectType: objectType,
+					Name:       name,
+					Err:        err,
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+
+	close(jobs)
+	wait.Wait()
+
+	return report, session.Commit()
+}
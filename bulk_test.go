@@ -0,0 +1,124 @@
+package gonagios
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulk_createHosts(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+	client.BulkConcurrency = 4
+
+	var hosts []*Host
+	for i := 0; i < 20; i++ {
+		hosts = append(hosts, createNamedHostObject("host"+strconv.Itoa(i)))
+	}
+
+	// An invalid host fails on its own without stopping the others
+	hosts[7].Address = ""
+
+	report, err := client.BulkCreateHosts(hosts)
+
+	var commitError *CommitError
+	assert.True(t, errors.As(err, &commitError))
+	assert.Len(t, commitError.Failed, 1)
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	assert.Len(t, report.Results, 20)
+	assert.Equal(t, "host7", report.Results[7].Name)
+	assert.Error(t, report.Results[7].Err)
+	assert.Len(t, report.Succeeded(), 19)
+	assert.Len(t, report.Failed(), 1)
+
+	assert.Len(t, server.Objects("host"), 19)
+	assert.Equal(t, 1, server.Applies())
+}
+
+func TestBulk_updateAndDeleteHosts(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+	server.Add("host", map[string]string{"host_name": "host2", "address": "127.0.0.2"})
+
+	client := NewClient(server.URL, "token123")
+
+	report, err := client.BulkUpdateHosts([]*Host{
+		{HostName: "host1", Address: "10.0.0.1"},
+		{HostName: "host2", Address: "10.0.0.2"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Succeeded(), 2)
+	assert.Equal(t, "10.0.0.2", server.Find("host", "host2")["address"])
+
+	_, err = client.BulkDeleteHosts([]string{"host1", "host2", "missing"})
+
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Empty(t, server.Objects("host"))
+	assert.Equal(t, 2, server.Applies())
+}
+
+func TestBulk_objects(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+
+	services := []ConfigObject{
+		{Type: ObjectService, Attributes: map[string]string{"host_name": "host1", "service_description": "PING", "use": "generic-service", "check_command": "check_ping"}},
+		{Type: ObjectService, Attributes: map[string]string{"host_name": "host1", "service_description": "HTTP", "use": "generic-service", "check_command": "check_http"}},
+		{Type: ObjectService, Attributes: map[string]string{"host_name": "host1"}},
+	}
+
+	report, err := client.BulkCreateObjects(services)
+
+	assert.Error(t, err)
+	assert.Equal(t, "host1 PING", report.Results[0].Name)
+	assert.Len(t, report.Failed(), 1)
+	assert.Len(t, server.Objects("service"), 2)
+
+	services[1].Attributes["check_command"] = "check_https"
+
+	_, err = client.BulkUpdateObjects(services[1:2])
+
+	assert.NoError(t, err)
+	assert.Equal(t, "check_https", server.Find("service", "host1", "HTTP")["check_command"])
+
+	_, err = client.BulkDeleteObjects(services[:2])
+
+	assert.NoError(t, err)
+	assert.Empty(t, server.Objects("service"))
+	assert.Equal(t, 3, server.Applies())
+
+	// Without templates XI requires every attribute a service needs
+	_, err = client.BulkCreateObjects([]ConfigObject{
+		{Type: ObjectService, Attributes: map[string]string{"host_name": "host1", "service_description": "SSH", "check_command": "check_ssh"}},
+	})
+
+	var apiError *APIError
+
+	assert.True(t, errors.As(err, &apiError))
+	assert.Contains(t, apiError.Missing, "max_check_attempts")
+	assert.Empty(t, server.Objects("service"))
+}
+
+func TestBulk_empty(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+
+	report, err := client.BulkCreateHosts(nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, report.Results)
+	assert.Equal(t, 0, server.Applies())
+}
@@ -0,0 +1,322 @@
+// Package cfgparse reads native Nagios object configuration files, the define host { ... } blocks of
+// Nagios Core, into ConfigObjects that remember the file and line they came from
+//
+//	config, err := cfgparse.Load("/usr/local/nagios/etc/nagios.cfg")
+//
+//	for _, object := range config.Objects {
+//		fmt.Println(object.Position, object.Type, object.Name())
+//	}
+//
+// Comments start with # or ; at the beginning of a line, and ; also starts a comment after a directive
+// unless it is escaped as \;. A line ending with a backslash continues on the next line. Values are kept
+// as written, including the + that makes an inherited list additive; use List to split lists such as
+// members or contact_groups and Additive to check for the +. A directive set twice in one definition is
+// an error. Objects are not resolved against their templates
+package cfgparse
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// Position is where something was read from
+type Position struct {
+	File string
+	Line int
+}
+
+// String returns the position as file:line
+func (position Position) String() string {
+	return position.File + ":" + strconv.Itoa(position.Line)
+}
+
+// SyntaxError is returned when a file cannot be parsed
+type SyntaxError struct {
+	Position Position
+	Message  string
+}
+
+// Error returns the message prefixed with the position
+func (syntaxError *SyntaxError) Error() string {
+	return syntaxError.Position.String() + ": " + syntaxError.Message
+}
+
+// Object is an object definition read from a file
+type Object struct {
+	gonagios.ConfigObject
+	// Position is where the define line is
+	Position Position
+	// Positions holds where each attribute was set
+	Positions map[string]Position
+}
+
+// List splits a comma separated value, such as the members of a group, into its items
+// The + of an additive list is left out, see Additive
+func (object *Object) List(attribute string) []string {
+	var items []string
+
+	value := strings.TrimPrefix(strings.TrimSpace(object.Attributes[attribute]), "+")
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Additive returns true when a list starts with +, which adds its items to the ones inherited from the
+// templates instead of replacing them
+func (object *Object) Additive(attribute string) bool {
+	return strings.HasPrefix(strings.TrimSpace(object.Attributes[attribute]), "+")
+}
+
+// Host converts a host definition to a Host. Errors are prefixed with the position of the definition
+func (object *Object) Host() (*gonagios.Host, error) {
+	host, err := object.ConfigObject.Host()
+
+	if err != nil {
+		return nil, &SyntaxError{Position: object.Position, Message: err.Error()}
+	}
+
+	return host, nil
+}
+
+// objectTypes are the object types Nagios Core accepts in a define block
+var objectTypes = map[gonagios.ObjectType]bool{
+	gonagios.ObjectHost:              true,
+	gonagios.ObjectService:           true,
+	gonagios.ObjectHostGroup:         true,
+	gonagios.ObjectServiceGroup:      true,
+	gonagios.ObjectCommand:           true,
+	gonagios.ObjectContact:           true,
+	gonagios.ObjectContactGroup:      true,
+	gonagios.ObjectTimePeriod:        true,
+	gonagios.ObjectHostDependency:    true,
+	gonagios.ObjectServiceDependency: true,
+	gonagios.ObjectHostEscalation:    true,
+	gonagios.ObjectServiceEscalation: true,
+	"hostextinfo":                    true,
+	"serviceextinfo":                 true,
+}
+
+// timePeriodDirectives are the timeperiod directives that are not time ranges
+var timePeriodDirectives = map[string]bool{
+	"timeperiod_name": true,
+	"alias":           true,
+	"name":            true,
+	"use":             true,
+	"register":        true,
+	"exclude":         true,
+}
+
+// defineLine matches the start of an object definition, with or without its opening brace
+var defineLine = regexp.MustCompile(`^define\s+([A-Za-z_]+)\s*(\{)?$`)
+
+// line is a logical line of a file, with continuations joined and comments removed
+type line struct {
+	text     string
+	position Position
+}
+
+// Parse reads the object definitions in r. The name is used in positions, and include_file and include_dir
+// directives are resolved relative to its directory
+func Parse(r io.Reader, name string) ([]Object, error) {
+	reader := newLoader()
+
+	if err := reader.parse(r, name); err != nil {
+		return nil, err
+	}
+
+	return reader.config.Objects, nil
+}
+
+// parse reads the object definitions of a file into the loader's configuration
+func (reader *loader) parse(r io.Reader, name string) error {
+	lines, err := readLines(r, name)
+
+	if err != nil {
+		return err
+	}
+
+	var object *Object
+	var opening *line
+
+	for i := range lines {
+		current := &lines[i]
+
+		switch {
+		case opening != nil:
+			if current.text != "{" {
+				return &SyntaxError{Position: current.position, Message: "expected { after " + opening.text}
+			}
+			opening = nil
+
+		case object == nil:
+			if match := defineLine.FindStringSubmatch(current.text); match != nil {
+				objectType := gonagios.ObjectType(match[1])
+
+				if !objectTypes[objectType] {
+					return &SyntaxError{Position: current.position, Message: "unknown object type '" + match[1] + "'"}
+				}
+
+				object = &Object{
+					ConfigObject: gonagios.ConfigObject{Type: objectType, Attributes: map[string]string{}},
+					Position:     current.position,
+					Positions:    map[string]Position{},
+				}
+
+				if match[2] == "" {
+					opening = current
+				}
+				continue
+			}
+
+			if err := reader.include(current, filepath.Dir(name)); err != nil {
+				return err
+			}
+
+		case strings.HasPrefix(current.text, "}"):
+			if strings.TrimSpace(current.text[1:]) != "" {
+				return &SyntaxError{Position: current.position, Message: "unexpected text after }"}
+			}
+			reader.config.Objects = append(reader.config.Objects, *object)
+			object = nil
+
+		default:
+			directive, value := splitDirective(object.Type, current.text)
+
+			if first, ok := object.Positions[directive]; ok {
+				return &SyntaxError{Position: current.position, Message: "'" + directive + "' is already set on line " + strconv.Itoa(first.Line)}
+			}
+
+			object.Attributes[directive] = value
+			object.Positions[directive] = current.position
+		}
+	}
+
+	if object != nil {
+		return &SyntaxError{Position: object.Position, Message: "define " + string(object.Type) + " is not closed with }"}
+	}
+
+	if opening != nil {
+		return &SyntaxError{Position: opening.position, Message: "expected { after " + opening.text}
+	}
+
+	return nil
+}
+
+// include reads the file or directory named by an include_file or include_dir directive outside of
+// an object definition. Anything else outside of a definition is an error
+func (reader *loader) include(current *line, dir string) error {
+	index := strings.Index(current.text, "=")
+
+	if index > 0 {
+		directive := strings.TrimSpace(current.text[:index])
+		path := resolve(dir, strings.TrimSpace(current.text[index+1:]))
+
+		switch directive {
+		case "include_file":
+			return reader.parseFile(path, current.position)
+		case "include_dir":
+			return reader.parseDir(path, current.position)
+		}
+	}
+
+	return &SyntaxError{Position: current.position, Message: "unexpected '" + current.text + "' outside of an object definition"}
+}
+
+// splitDirective separates the name of a directive from its value
+// Timeperiod time ranges, such as "december 25 00:00-24:00", are named by everything before the first range
+func splitDirective(objectType gonagios.ObjectType, text string) (string, string) {
+	fields := strings.Fields(text)
+
+	if objectType == gonagios.ObjectTimePeriod && !timePeriodDirectives[fields[0]] {
+		for i := 1; i < len(fields); i++ {
+			if strings.Contains(fields[i], ":") {
+				return strings.Join(fields[:i], " "), strings.Join(fields[i:], " ")
+			}
+		}
+	}
+
+	return fields[0], strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+}
+
+// maxLineLength is the longest line that can be read, long enough for generated member lists
+const maxLineLength = 1024 * 1024
+
+// newScanner returns a scanner that reads lines of up to maxLineLength
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+
+	return scanner
+}
+
+// readLines reads the logical lines of a file: continuations are joined, comments are removed and
+// blank lines are skipped. A logical line has the position of its first physical line
+func readLines(r io.Reader, name string) ([]line, error) {
+	scanner := newScanner(r)
+
+	var lines []line
+	var pending strings.Builder
+	start := 0
+
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if pending.Len() == 0 {
+			start = number
+		}
+
+		if strings.HasSuffix(text, "\\") && !strings.HasSuffix(text, "\\\\") {
+			pending.WriteString(strings.TrimSuffix(text, "\\"))
+			continue
+		}
+
+		pending.WriteString(text)
+		text = stripComment(pending.String())
+		pending.Reset()
+
+		if text != "" {
+			lines = append(lines, line{text: text, position: Position{File: name, Line: start}})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if pending.Len() > 0 {
+		if text := stripComment(pending.String()); text != "" {
+			lines = append(lines, line{text: text, position: Position{File: name, Line: start}})
+		}
+	}
+
+	return lines, nil
+}
+
+// stripComment removes a comment and surrounding whitespace from a line, and unescapes \;
+func stripComment(text string) string {
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+		return ""
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ';' && (i == 0 || text[i-1] != '\\') {
+			text = text[:i]
+			break
+		}
+	}
+
+	return strings.TrimSpace(strings.Replace(text, "\\;", ";", -1))
+}
@@ -0,0 +1,114 @@
+package cfgparse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/stretchr/testify/assert"
+)
+
+const testObjects = `# Hosts of the web tier
+define host{
+    use                 generic-host    ; inherit the defaults
+    host_name           web01
+    alias               Web server \; frontend
+    address             10.0.0.1
+    parents             router1, router2
+    contact_groups      +admins
+    notes_url           http://wiki/web01#network
+    _SNMP_COMMUNITY     public
+    check_command       check_http!-u /health \
+                        -e 200
+}
+
+; a template
+define host
+{
+    name                generic-host
+    register            0
+    max_check_attempts  3
+}
+
+define timeperiod {
+    timeperiod_name     workhours
+    monday              09:00-17:00
+    december 25         00:00-00:00
+    2019-12-31 - 2020-01-02 / 2   00:00-24:00
+}
+`
+
+func TestParse_objects(t *testing.T) {
+	objects, err := Parse(strings.NewReader(testObjects), "hosts.cfg")
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 3)
+
+	web01 := objects[0]
+
+	assert.Equal(t, gonagios.ObjectHost, web01.Type)
+	assert.Equal(t, "web01", web01.Name())
+	assert.Equal(t, Position{File: "hosts.cfg", Line: 2}, web01.Position)
+	assert.Equal(t, "hosts.cfg:5", web01.Positions["alias"].String())
+	assert.Equal(t, "generic-host", web01.Attributes["use"])
+	assert.Equal(t, "Web server ; frontend", web01.Attributes["alias"])
+	assert.Equal(t, "http://wiki/web01#network", web01.Attributes["notes_url"])
+	assert.Equal(t, "check_http!-u /health                         -e 200", web01.Attributes["check_command"])
+	assert.Equal(t, 11, web01.Positions["check_command"].Line)
+	assert.Equal(t, []string{"router1", "router2"}, web01.List("parents"))
+	assert.Equal(t, "+admins", web01.Attributes["contact_groups"])
+	assert.Equal(t, []string{"admins"}, web01.List("contact_groups"))
+	assert.True(t, web01.Additive("contact_groups"))
+	assert.False(t, web01.Additive("parents"))
+
+	template := objects[1]
+
+	assert.Nil(t, template.Names())
+	assert.Equal(t, "generic-host", template.Attributes["name"])
+	assert.Equal(t, 16, template.Position.Line)
+
+	period := objects[2]
+
+	assert.Equal(t, map[string]string{
+		"timeperiod_name":             "workhours",
+		"monday":                      "09:00-17:00",
+		"december 25":                 "00:00-00:00",
+		"2019-12-31 - 2020-01-02 / 2": "00:00-24:00",
+	}, period.Attributes)
+}
+
+func TestParse_host(t *testing.T) {
+	objects, err := Parse(strings.NewReader(testObjects), "hosts.cfg")
+	assert.NoError(t, err)
+
+	host, err := objects[0].Host()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "web01", host.HostName)
+	assert.Equal(t, []interface{}{"generic-host"}, host.Templates)
+	assert.Equal(t, map[string]interface{}{"_SNMP_COMMUNITY": "public"}, host.FreeVariables)
+
+	_, err = objects[2].Host()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "hosts.cfg:23")
+}
+
+func TestParse_syntaxErrors(t *testing.T) {
+	tests := map[string]string{
+		"define host {\n  host_name web01\n":           "hosts.cfg:1: define host is not closed with }",
+		"define hots {\n}\n":                           "hosts.cfg:1: unknown object type 'hots'",
+		"host_name web01\n":                            "hosts.cfg:1: unexpected 'host_name web01' outside of an object definition",
+		"define host\n  host_name web01\n}\n":          "hosts.cfg:2: expected { after define host",
+		"define host {\n  host_name web01\n} extra\n":  "hosts.cfg:3: unexpected text after }",
+		"define host {\n  address a\n  address b\n}\n": "hosts.cfg:3: 'address' is already set on line 2",
+	}
+
+	for input, message := range tests {
+		_, err := Parse(strings.NewReader(input), "hosts.cfg")
+
+		var syntaxError *SyntaxError
+		assert.True(t, errors.As(err, &syntaxError), input)
+		assert.EqualError(t, err, message)
+	}
+}
@@ -0,0 +1,209 @@
+package cfgparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// Config is everything read from a main configuration file and the object files it includes
+type Config struct {
+	Objects []Object
+	// Files lists every object file that was read, in the order it was read
+	Files []string
+	// Main holds the directives of the main configuration file other than cfg_file and cfg_dir, such as
+	// resource_file or illegal_object_name_chars. A directive can be set more than once
+	Main map[string][]string
+}
+
+// Types returns the objects of a type, in the order they were read
+func (config *Config) Types(objectType gonagios.ObjectType) []Object {
+	var objects []Object
+
+	for i := range config.Objects {
+		if config.Objects[i].Type == objectType {
+			objects = append(objects, config.Objects[i])
+		}
+	}
+
+	return objects
+}
+
+// loader reads files into a configuration, reading each file once
+type loader struct {
+	config *Config
+	seen   map[string]bool
+}
+
+// newLoader starts an empty configuration
+func newLoader() *loader {
+	return &loader{
+		config: &Config{Main: map[string][]string{}},
+		seen:   map[string]bool{},
+	}
+}
+
+// Load reads a main configuration file such as nagios.cfg, and every object file named by its cfg_file and
+// cfg_dir directives. Relative paths are resolved from the directory of the main configuration file, and
+// cfg_dir reads every file ending in .cfg in the directory and its subdirectories
+func Load(mainPath string) (*Config, error) {
+	reader := newLoader()
+
+	file, err := os.Open(mainPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	lines, err := readMainLines(file, mainPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(mainPath)
+
+	for _, current := range lines {
+		index := strings.Index(current.text, "=")
+
+		if index <= 0 {
+			return nil, &SyntaxError{Position: current.position, Message: "expected name=value"}
+		}
+
+		directive := strings.TrimSpace(current.text[:index])
+		value := strings.TrimSpace(current.text[index+1:])
+
+		switch directive {
+		case "cfg_file":
+			err = reader.parseFile(resolve(dir, value), current.position)
+		case "cfg_dir":
+			err = reader.parseDir(resolve(dir, value), current.position)
+		default:
+			reader.config.Main[directive] = append(reader.config.Main[directive], value)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reader.config, nil
+}
+
+// ParseFiles reads object configuration files and directories without a main configuration file
+// Directories are read like cfg_dir
+func ParseFiles(paths ...string) (*Config, error) {
+	reader := newLoader()
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			err = reader.parseDir(path, Position{})
+		} else {
+			err = reader.parseFile(path, Position{})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reader.config, nil
+}
+
+// parseFile reads an object file, unless it was already read
+// from is the directive that named the file, for errors, and is empty for files given directly
+func (reader *loader) parseFile(path string, from Position) error {
+	path = filepath.Clean(path)
+
+	if reader.seen[path] {
+		return nil
+	}
+
+	reader.seen[path] = true
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		if from.File != "" {
+			return &SyntaxError{Position: from, Message: err.Error()}
+		}
+		return err
+	}
+
+	defer file.Close()
+
+	reader.config.Files = append(reader.config.Files, path)
+
+	return reader.parse(file, path)
+}
+
+// parseDir reads every file ending in .cfg in a directory and its subdirectories, in name order
+func (reader *loader) parseDir(dir string, from Position) error {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".cfg") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if from.File != "" {
+			return &SyntaxError{Position: from, Message: err.Error()}
+		}
+		return err
+	}
+
+	for _, file := range files {
+		if err := reader.parseFile(file, from); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readMainLines reads the lines of a main configuration file, where only whole lines are comments
+// since values such as illegal_object_name_chars may contain ;
+func readMainLines(file *os.File, name string) ([]line, error) {
+	scanner := newScanner(file)
+
+	var lines []line
+
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		lines = append(lines, line{text: text, position: Position{File: name, Line: number}})
+	}
+
+	return lines, scanner.Err()
+}
+
+// resolve returns path, relative to dir unless it is absolute
+func resolve(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
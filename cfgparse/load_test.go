@@ -0,0 +1,126 @@
+package cfgparse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles creates files under dir from a map of relative paths to contents
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLoad_includes(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"nagios.cfg": strings.Join([]string{
+			"# main configuration",
+			"log_file=/var/log/nagios.log",
+			"cfg_file=objects/commands.cfg",
+			"cfg_dir=servers",
+			"cfg_file=objects/commands.cfg",
+			"resource_file=resource.cfg",
+			"illegal_object_name_chars=`~!$%^&*|'\"<>?,()=",
+			"",
+		}, "\n"),
+		"objects/commands.cfg":  "define command {\n  command_name check_ping\n  command_line $USER1$/check_ping -H $HOSTADDRESS$\n}\n",
+		"servers/web/web01.cfg": "define host {\n  host_name web01\n}\ninclude_file=../../shared/extra.cfg\n",
+		"servers/db01.cfg":      "define host {\n  host_name db01\n}\n",
+		"servers/README.txt":    "not a config file",
+		"shared/extra.cfg":      "define service {\n  host_name web01\n  service_description PING\n}\n",
+	})
+
+	config, err := Load(filepath.Join(dir, "nagios.cfg"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "objects/commands.cfg"),
+		filepath.Join(dir, "servers/db01.cfg"),
+		filepath.Join(dir, "servers/web/web01.cfg"),
+		filepath.Join(dir, "shared/extra.cfg"),
+	}, config.Files)
+
+	var names []string
+	for _, object := range config.Objects {
+		names = append(names, string(object.Type)+" "+object.Name())
+	}
+
+	assert.Equal(t, []string{"command check_ping", "host db01", "host web01", "service web01 PING"}, names)
+	assert.Len(t, config.Types(gonagios.ObjectHost), 2)
+	assert.Equal(t, filepath.Join(dir, "shared/extra.cfg"), config.Objects[3].Position.File)
+	assert.Equal(t, []string{"resource.cfg"}, config.Main["resource_file"])
+	assert.Equal(t, []string{"`~!$%^&*|'\"<>?,()="}, config.Main["illegal_object_name_chars"])
+	assert.NotContains(t, config.Main, "cfg_file")
+}
+
+func TestLoad_longLines(t *testing.T) {
+	dir := t.TempDir()
+
+	members := strings.Repeat("web,", 50000) + "web"
+
+	writeFiles(t, dir, map[string]string{
+		"nagios.cfg": "cfg_file=groups.cfg\nillegal_macro_output_chars=" + strings.Repeat("~", 100000) + "\n",
+		"groups.cfg": "define hostgroup {\n  hostgroup_name all\n  members " + members + "\n}\n",
+	})
+
+	config, err := Load(filepath.Join(dir, "nagios.cfg"))
+
+	assert.NoError(t, err)
+	assert.Len(t, config.Main["illegal_macro_output_chars"][0], 100000)
+	assert.Equal(t, members, config.Objects[0].Attributes["members"])
+}
+
+func TestLoad_missingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"nagios.cfg": "cfg_file=objects/missing.cfg\n",
+	})
+
+	_, err := Load(filepath.Join(dir, "nagios.cfg"))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "nagios.cfg:1: ")
+}
+
+func TestLoad_syntaxErrorInObjectFile(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"nagios.cfg":      "cfg_dir=objects\n",
+		"objects/bad.cfg": "\n\ndefine host {\n",
+	})
+
+	_, err := Load(filepath.Join(dir, "nagios.cfg"))
+
+	assert.EqualError(t, err, filepath.Join(dir, "objects/bad.cfg")+":3: define host is not closed with }")
+}
+
+func TestParseFiles_withoutMain(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"hosts/web01.cfg": "define host {\n  host_name web01\n}\n",
+		"commands.cfg":    "define command {\n  command_name check_ping\n}\n",
+	})
+
+	config, err := ParseFiles(filepath.Join(dir, "commands.cfg"), filepath.Join(dir, "hosts"))
+
+	assert.NoError(t, err)
+	assert.Len(t, config.Objects, 2)
+	assert.Empty(t, config.Main)
+
+	_, err = ParseFiles(filepath.Join(dir, "missing.cfg"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,22 +1,54 @@
 package gonagios
 
 import (
-	"io/ioutil"
+	"context"
+	"io"
 	"net/http"
 	"net/url"
-	"reflect"
-	"strconv"
 	"strings"
 	"time"
 )
 
 // Client used to store info required to communicate with Nagios
+// Limiter is shared by every request the client sends. ApplyConfigLimiter is applied on top of it
+// for applyConfig, which restarts the Nagios core and is far more expensive than any other call
+// Middleware wraps every attempt to send a request, see LoggingMiddleware, MetricsMiddleware and TracingMiddleware
+// APIKeyLocation controls how the token is sent. Keeping it out of the URL stops it from leaking into proxy access logs
+// When SnapshotDir is set, the objects affected by a change (or every object when SnapshotAll is set) are saved
+// there before the change is made, see Restore
+// BulkConcurrency is how many requests the bulk operations send at once, DefaultBulkConcurrency when zero
+// When Owner is set, every object the client creates is marked as owned, see Ownership and Prune
 type Client struct {
-	URL        string
-	Token      string
-	httpClient *http.Client
+	URL                string
+	Token              string
+	APIKeyLocation     APIKeyLocation
+	RetryPolicy        *RetryPolicy
+	Limiter            *Limiter
+	ApplyConfigLimiter *Limiter
+	Middleware         []Middleware
+	SnapshotDir        string
+	SnapshotAll        bool
+	BulkConcurrency    int
+	Owner              *Ownership
+	httpClient         *http.Client
 }
 
+// APIKeyLocation determines where the API key is sent in each request
+type APIKeyLocation int
+
+// Locations the API key can be sent in
+const (
+	// APIKeyInQuery sends the key as the 'apikey' URL query parameter, which every XI version accepts
+	APIKeyInQuery APIKeyLocation = iota
+	// APIKeyInHeader sends the key in the APIKeyHeader HTTP header
+	APIKeyInHeader
+	// APIKeyInBody sends the key in the form body of POST requests and in the URL for all other methods
+	APIKeyInBody
+)
+
+// APIKeyHeader is the HTTP header used when the client is set to APIKeyInHeader
+const APIKeyHeader = "X-Api-Key"
+
 // NewClient creates a pointer to the client that will be used to send requests to Nagios
 func NewClient(url, token string) *Client {
 	httpClient := &http.Client{
@@ -24,9 +56,10 @@ func NewClient(url, token string) *Client {
 	}
 
 	nagiosClient := &Client{
-		URL:        url,
-		Token:      token,
-		httpClient: httpClient,
+		URL:         url,
+		Token:       token,
+		RetryPolicy: DefaultRetryPolicy(),
+		httpClient:  httpClient,
 	}
 
 	return nagiosClient
@@ -34,26 +67,25 @@ func NewClient(url, token string) *Client {
 
 // sendRequest executes a HTTP request to the API endpoint
 func (client *Client) sendRequest(httpRequest *http.Request) ([]byte, error) {
-	addRequestHeaders(httpRequest)
+	client.addRequestHeaders(httpRequest)
 
-	response, err := client.httpClient.Do(httpRequest)
+	if err := client.Limiter.acquire(httpRequest.Context()); err != nil {
+		return nil, err
+	}
+
+	defer client.Limiter.release()
+
+	response, err := client.doWithRetry(httpRequest)
 
-	// TODO: Need to validate that when Nagios is unavailable, this err check will catch it
 	if err != nil {
 		return nil, err
 	}
 
 	defer response.Body.Close()
 
-	var body []byte
-
 	// Nagios returns data in the body during a HTTP GET and we need to unmarshal that data to its respective struct downstream
 	// If we are doing a POST, PUT or DELETE, Nagios will return a 'success' or 'error' status code, so we want to capture the message
-	if httpRequest.Method == http.MethodGet {
-		body, err = ioutil.ReadAll(response.Body)
-	} else {
-		body, err = parseAPIResponse(response.Body)
-	}
+	body, err := parseAPIResponse(response)
 
 	if err != nil {
 		return nil, err
@@ -64,51 +96,95 @@ func (client *Client) sendRequest(httpRequest *http.Request) ([]byte, error) {
 
 // buildURL creates the URL to communicate with the Nagios API
 // Depending on object type and HTTP method, the URL will vary
+// Object names are escaped as path segments, so names containing '/', '#', '&' or unicode are safe
 func (client *Client) buildURL(apiType, objectType, methodType string, objectInfo ...string) string {
+	// config or system for API type, followed by the Nagios object type (host, service, etc)
+	segments := []string{apiType, objectType}
+
+	// For PUT and DELETE, we have to append the object's name to the URL
+	if methodType == http.MethodPut || methodType == http.MethodDelete {
+		if len(objectInfo) > 0 {
+			segments = append(segments, objectInfo[0])
+			if objectType == "service" && len(objectInfo) > 1 { // If it's a service, we need to tack on the service description
+				segments = append(segments, objectInfo[1])
+			}
+		}
+	}
+
 	var nagiosURL strings.Builder
 
-	nagiosURL.WriteString(client.URL)
+	nagiosURL.WriteString(strings.TrimSuffix(client.URL, "/"))
+	nagiosURL.WriteString("/api/v1")
 
-	if strings.HasSuffix(client.URL, "/") {
-		nagiosURL.WriteString("api/v1/")
-	} else {
-		nagiosURL.WriteString("/api/v1/")
+	for _, segment := range segments {
+		nagiosURL.WriteString("/" + url.PathEscape(segment))
 	}
 
-	// config or system for API type
-	nagiosURL.WriteString(apiType + "/")
+	query := url.Values{}
 
-	// Nagios object type (host, service, etc)
-	nagiosURL.WriteString(objectType + "/")
-
-	// For PUT and DELETE, we have to do a few extra things
-	if methodType == http.MethodPut || methodType == http.MethodDelete {
-		if objectInfo != nil {
-			// Append the object's name to the URL
-			nagiosURL.WriteString(objectInfo[0])
-			if objectType == "service" { // If it's a service, we need to tack on the service description
-				nagiosURL.WriteString("/" + objectInfo[1])
-			}
-		}
+	if client.apiKeyInURL(methodType) {
+		query.Set("apikey", client.Token)
 	}
 
-	nagiosURL.WriteString("?apikey=" + client.Token)
-	nagiosURL.WriteString("&pretty=1")
+	query.Set("pretty", "1")
+
+	nagiosURL.WriteString("?" + query.Encode())
 
 	return nagiosURL.String()
 }
 
+// addQueryParams appends URL query parameters to a URL created by buildURL
+func addQueryParams(nagiosURL string, params url.Values) string {
+	if len(params) == 0 {
+		return nagiosURL
+	}
+
+	return nagiosURL + "&" + params.Encode()
+}
+
+// apiKeyInURL returns true if the API key has to be sent as a URL query parameter
+// XI only reads form bodies on POST, so APIKeyInBody falls back to the URL for every other method
+func (client *Client) apiKeyInURL(methodType string) bool {
+	switch client.APIKeyLocation {
+	case APIKeyInHeader:
+		return false
+	case APIKeyInBody:
+		return methodType != http.MethodPost
+	}
+
+	return true
+}
+
 // addRequestHeaders adds the required headers to the HTTP request
-func addRequestHeaders(request *http.Request) {
+func (client *Client) addRequestHeaders(request *http.Request) {
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	request.Header.Add("Accept", "/")
 
-	return
+	if client.APIKeyLocation == APIKeyInHeader {
+		request.Header.Set(APIKeyHeader, client.Token)
+	}
+}
+
+// newRequest creates a HTTP request that is cancelled along with ctx
+// A URL that cannot be parsed is quoted in the error, so the API key is removed from it
+func newRequest(ctx context.Context, method, nagiosURL string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, nagiosURL, body)
+
+	if err != nil {
+		return nil, redactError(err)
+	}
+
+	return request, nil
 }
 
 // get executes a HTTP GET against the API endpoint
 func (client *Client) get(data, nagiosURL string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodGet, nagiosURL, strings.NewReader(data))
+	return client.getContext(context.Background(), data, nagiosURL)
+}
+
+// getContext executes a HTTP GET against the API endpoint that is cancelled along with ctx
+func (client *Client) getContext(ctx context.Context, data, nagiosURL string) ([]byte, error) {
+	request, err := newRequest(ctx, http.MethodGet, nagiosURL, strings.NewReader(data))
 
 	if err != nil {
 		return nil, err
@@ -125,7 +201,22 @@ func (client *Client) get(data, nagiosURL string) ([]byte, error) {
 
 // post executes a HTTP POST against the API endpoint
 func (client *Client) post(data *url.Values, nagiosURL string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodPost, nagiosURL, strings.NewReader(data.Encode()))
+	return client.postContext(context.Background(), data, nagiosURL)
+}
+
+// postContext executes a HTTP POST against the API endpoint that is cancelled along with ctx
+func (client *Client) postContext(ctx context.Context, data *url.Values, nagiosURL string) ([]byte, error) {
+	if client.APIKeyLocation == APIKeyInBody {
+		// Copy the values so the caller's data is not modified and the key cannot end up in anything they log
+		withKey := url.Values{}
+		for key, values := range *data {
+			withKey[key] = values
+		}
+		withKey.Set("apikey", client.Token)
+		data = &withKey
+	}
+
+	request, err := newRequest(ctx, http.MethodPost, nagiosURL, strings.NewReader(data.Encode()))
 
 	if err != nil {
 		return nil, err
@@ -142,10 +233,7 @@ func (client *Client) post(data *url.Values, nagiosURL string) ([]byte, error) {
 
 // put executes a HTTP PUT against the API endpoint
 func (client *Client) put(nagiosURL string) ([]byte, error) {
-	if strings.Contains(nagiosURL, " ") {
-		nagiosURL = strings.Replace(nagiosURL, " ", "%20", -1)
-	}
-	request, err := http.NewRequest(http.MethodPut, nagiosURL, nil)
+	request, err := newRequest(context.Background(), http.MethodPut, nagiosURL, nil)
 
 	if err != nil {
 		return nil, err
@@ -162,7 +250,7 @@ func (client *Client) put(nagiosURL string) ([]byte, error) {
 
 // delete executes a HTTP DELETE against the API endpoint
 func (client *Client) delete(data *url.Values, nagiosURL string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodDelete, nagiosURL, strings.NewReader(data.Encode()))
+	request, err := newRequest(context.Background(), http.MethodDelete, nagiosURL, strings.NewReader(data.Encode()))
 
 	if err != nil {
 		return nil, err
@@ -179,34 +267,32 @@ func (client *Client) delete(data *url.Values, nagiosURL string) ([]byte, error)
 
 // applyConfig restarts the Nagios core engine and applies the latest changes to the configuration
 func (client *Client) applyConfig() error {
+	_, err := client.applyConfigContext(context.Background())
+
+	return err
+}
+
+// applyConfigContext sends the apply config command and returns the response from Nagios
+func (client *Client) applyConfigContext(ctx context.Context) ([]byte, error) {
 	nagiosURL := client.buildURL("system", "applyconfig", http.MethodPost)
 
 	data := &url.Values{}
 
-	_, err := client.post(data, nagiosURL)
+	// The request also goes through Limiter, so a limiter set as both must only be applied once,
+	// or a single slot would be taken here and never be free for the request itself
+	if client.ApplyConfigLimiter != client.Limiter {
+		if err := client.ApplyConfigLimiter.acquire(ctx); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return err
-	}
+		defer client.ApplyConfigLimiter.release()
 
-	return nil
-}
-
-// mapArrayToString maps the elements of a string array to a single string with each value separated by commas
-func mapArrayToString(sourceArray []interface{}) string {
-	var destString strings.Builder
-
-	for i, sourceObject := range sourceArray {
-		// If this is the first time looping through, set the destination object euqal to the first element in array
-		if i == 0 {
-			destString.WriteString(sourceObject.(string))
-		} else { // More than one element in array. Append a comma first before we add the next item
-			destString.WriteString(",")
-			destString.WriteString(sourceObject.(string))
+		if err := client.ApplyConfigLimiter.wait(ctx); err != nil {
+			return nil, err
 		}
 	}
 
-	return destString.String()
+	return client.postContext(ctx, data, nagiosURL)
 }
 
 // convertBoolToIntToString takes any boolean value, converts to integer and returns in string format
@@ -217,65 +303,3 @@ func convertBoolToIntToString(sourceVal bool) string {
 	}
 	return "0"
 }
-
-// setURLParams loops through a struct object and returns a set of URL parameters
-func setURLParams(nagiosObject interface{}) *url.Values {
-	values := reflect.ValueOf(nagiosObject)
-	var urlParams = &url.Values{}
-	var tag string
-
-	// If we are passing in a pointer to a struct, we need to get the actual result of what the struct is pointing to
-	if values.Kind() == reflect.Ptr {
-		values = values.Elem()
-	}
-
-	for i := 0; i < values.NumField(); i++ {
-		var outputString strings.Builder
-		curType := values.Field(i).Type().String()
-		tags := strings.Split(values.Type().Field(i).Tag.Get("json"), ",")
-
-		for k := range tags {
-			if tags[k] != "omitempty" {
-				tag = tags[k]
-				break
-			}
-		}
-
-		if curType == "string" {
-			if values.Field(i).Interface().(string) != "" {
-				urlParams.Add(tag, values.Field(i).Interface().(string))
-			}
-		} else if curType == "[]interface {}" {
-			if values.Field(i).Interface() != nil {
-				for j, val := range values.Field(i).Interface().([]interface{}) {
-					if j > 0 {
-						outputString.WriteString(",")
-					}
-
-					outputString.WriteString(val.(string))
-				}
-				urlParams.Add(tag, outputString.String())
-			}
-		} else if curType == "int" {
-			if strconv.Itoa(values.Field(i).Interface().(int)) != "" {
-				// We need the value to be a string but first need to cast it as an integer if that is what the type is in the struct
-				urlParams.Add(tag, strconv.Itoa(values.Field(i).Interface().(int)))
-			}
-		} else if curType == "map[string]interface {}" {
-			if values.Field(i).Interface() != nil {
-				// We need to loop through the map and grab the key and value for each line
-				// The value is an interface, so we need to then call the Interface() method
-				// and cast it as a string to get the value in string format
-				mapObject := values.Field(i).MapRange()
-				for mapObject.Next() {
-					outputString.Reset()
-					index := mapObject.Key().String()
-					val := mapObject.Value()
-					valString := val.Interface().(string)
-					urlParams.Add(index, valString)
-				}
-			}
-		}
-	}
-	return urlParams
-}
@@ -3,9 +3,11 @@ package gonagios
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -57,3 +59,78 @@ func envVarCheck() []string {
 
 	return errList
 }
+
+// newTestClient returns a client pointed at a local test server instead of a live Nagios instance
+func newTestClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	client := NewClient(server.URL, "token123")
+	client.RetryPolicy.BaseDelay = time.Millisecond
+	client.RetryPolicy.MaxDelay = 5 * time.Millisecond
+
+	return client, server
+}
+
+func TestClient_buildURLEscapesNames(t *testing.T) {
+	client := NewClient("https://nagios.domain.local/nagiosxi/", "to&ken")
+
+	nagiosURL := client.buildURL("config", "service", http.MethodPut, "web/01#a&b", "Disk Ä /")
+
+	assert.Equal(t, "https://nagios.domain.local/nagiosxi/api/v1/config/service/web%2F01%23a&b/Disk%20%C3%84%20%2F?apikey=to%26ken&pretty=1", nagiosURL)
+
+	parsed, err := url.Parse(nagiosURL)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/nagiosxi/api/v1/config/service/web/01#a&b/Disk Ä /", parsed.Path)
+	assert.Equal(t, "to&ken", parsed.Query().Get("apikey"))
+}
+
+func TestClient_updateHostQuery(t *testing.T) {
+	var query url.Values
+	var path string
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			query = r.URL.Query()
+			path = r.URL.EscapedPath()
+		}
+		w.Write([]byte(`{"success":"ok"}`))
+	})
+	defer server.Close()
+
+	err := client.UpdateHost(&Host{HostName: "new host", Notes: "a&b"}, "old host")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/v1/config/host/old%20host", path)
+	assert.Equal(t, "1", query.Get("pretty"))
+	assert.Equal(t, "new host", query.Get("host_name"))
+	assert.Equal(t, "a&b", query.Get("notes"))
+}
+
+func TestClient_apiKeyLocation(t *testing.T) {
+	var requests []*http.Request
+	var forms []url.Values
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, r)
+		forms = append(forms, r.PostForm)
+		w.Write([]byte(`{"success":"ok"}`))
+	})
+	defer server.Close()
+
+	client.APIKeyLocation = APIKeyInHeader
+	_, err := client.DeleteHost("host1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token123", requests[0].Header.Get(APIKeyHeader))
+	assert.Empty(t, requests[0].URL.Query().Get("apikey"))
+
+	client.APIKeyLocation = APIKeyInBody
+	requests, forms = nil, nil
+	_, err = client.post(&url.Values{}, client.buildURL("system", "applyconfig", http.MethodPost))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token123", forms[0].Get("apikey"))
+	assert.Empty(t, requests[0].URL.Query().Get("apikey"))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// runAck acknowledges the problem of a host, or of one of its services, or removes the acknowledgement
+func runAck(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("ack", "<host name>", stderr)
+	conn := addConnectionFlags(flags)
+	service := flags.String("service", "", "acknowledge a problem of this service instead of the host")
+	comment := flags.String("comment", "", "why the problem is acknowledged (required)")
+	author := flags.String("author", defaultAuthor(), "who acknowledges the problem")
+	sticky := flags.Bool("sticky", false, "keep the acknowledgement until the host or service recovers")
+	notify := flags.Bool("notify", false, "notify the contacts")
+	persistent := flags.Bool("persistent", false, "keep the comment when Nagios restarts")
+	remove := flags.Bool("remove", false, "remove the acknowledgement instead")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	target := flags.Arg(0)
+	if *service != "" {
+		target += " " + *service
+	}
+
+	if *remove {
+		if err := client.RemoveAcknowledgement(flags.Arg(0), *service); err != nil {
+			return fail(stderr, err)
+		}
+
+		io.WriteString(stdout, "Removed acknowledgement of "+target+"\n")
+
+		return exitOK
+	}
+
+	err = client.Acknowledge(&gonagios.Acknowledgement{
+		HostName:           flags.Arg(0),
+		ServiceDescription: *service,
+		Author:             *author,
+		Comment:            *comment,
+		Sticky:             *sticky,
+		Notify:             *notify,
+		Persistent:         *persistent,
+	})
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	io.WriteString(stdout, "Acknowledged "+target+"\n")
+
+	return exitOK
+}
+
+// defaultAuthor is the user running the command
+func defaultAuthor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+
+	return "gonagios"
+}
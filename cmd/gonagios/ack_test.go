@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAck_acknowledgeAndRemove(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	code, stdout, stderr := runCommand("ack", "-comment", "looking", "-author", "oncall", "-sticky", "web01")
+
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Acknowledged web01\n", stdout)
+
+	code, stdout, _ = runCommand("ack", "-remove", "-service", "HTTP", "web01")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Removed acknowledgement of web01 HTTP\n", stdout)
+
+	code, _, stderr = runCommand("ack", "-author", "oncall", "web01")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "comment: is required")
+
+	assert.Equal(t, []string{
+		"ACKNOWLEDGE_HOST_PROBLEM;web01;2;0;0;oncall;looking",
+		"REMOVE_SVC_ACKNOWLEDGEMENT;web01;HTTP",
+	}, server.Commands())
+}
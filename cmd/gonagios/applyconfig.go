@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// runApplyConfig applies the configuration, and optionally waits for the Nagios core to restart
+// When the new configuration is rejected the error includes the output of the verification
+func runApplyConfig(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("apply-config", "", stderr)
+	conn := addConnectionFlags(flags)
+	wait := flags.Bool("wait", false, "wait until the Nagios core has restarted with the new configuration")
+	timeout := flags.Duration("timeout", gonagios.DefaultApplyConfigTimeout, "how long to wait with -wait")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if !*wait {
+		if err := client.ApplyConfig(); err != nil {
+			return fail(stderr, err)
+		}
+
+		io.WriteString(stdout, "Apply config sent\n")
+
+		return exitOK
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	result, err := client.ApplyConfigAndWait(ctx)
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	io.WriteString(stdout, "Configuration applied in "+result.Duration.Round(time.Second).String()+"\n")
+
+	return exitOK
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyConfig_send(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	code, stdout, _ := runCommand("apply-config")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Apply config sent\n", stdout)
+	assert.Equal(t, 1, server.Applies())
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// config is the configuration file, which holds named connection profiles:
+//
+//	default: production
+//	profiles:
+//	  production:
+//	    url: https://nagios.domain.local/nagiosxi
+//	    token: token123
+//	  lab:
+//	    url: https://nagios-lab.domain.local/nagiosxi
+//	    token: token456
+type config struct {
+	// Default is the profile used when none is selected
+	Default  string             `yaml:"default"`
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+// profile says how to reach one XI instance
+type profile struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+}
+
+// defaultConfigPath returns where the configuration file is read from when -config and GONAGIOS_CONFIG are not set
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "gonagios", "config.yaml")
+}
+
+// loadConfig reads the configuration file
+// A missing file is only an error when required is set, otherwise it reads as an empty configuration
+func loadConfig(path string, required bool) (*config, error) {
+	cfg := &config{}
+
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) && !required {
+		return cfg, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+
+	return cfg, nil
+}
+
+// profile returns a profile by name, or the default profile when name is empty
+// Without a name or a default profile an empty profile is returned
+func (cfg *config) profile(name, path string) (profile, error) {
+	if name == "" {
+		name = cfg.Default
+	}
+
+	if name == "" {
+		return profile{}, nil
+	}
+
+	selected, ok := cfg.Profiles[name]
+
+	if !ok {
+		return profile{}, errors.New("profile '" + name + "' is not defined in " + path)
+	}
+
+	return selected, nil
+}
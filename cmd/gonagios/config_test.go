@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `default: production
+profiles:
+  production:
+    url: https://nagios.domain.local/nagiosxi
+    token: token123
+  lab:
+    url: https://nagios-lab.domain.local/nagiosxi
+    token: token456
+`
+
+// resolveConnection parses connection flags and works out the URL and API key
+func resolveConnection(args ...string) (profile, error) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	conn := addConnectionFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return profile{}, err
+	}
+
+	return conn.resolve()
+}
+
+func TestConfig_profiles(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("NAGIOS_URL", "")
+	t.Setenv("API_TOKEN", "")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(testConfig), 0600))
+
+	settings, err := resolveConnection("-config", path)
+	assert.NoError(t, err)
+	assert.Equal(t, profile{URL: "https://nagios.domain.local/nagiosxi", Token: "token123"}, settings)
+
+	settings, err = resolveConnection("-config", path, "-profile", "lab")
+	assert.NoError(t, err)
+	assert.Equal(t, profile{URL: "https://nagios-lab.domain.local/nagiosxi", Token: "token456"}, settings)
+
+	settings, err = resolveConnection("-config", path, "-profile", "lab", "-url", "http://other.local", "-token", "override")
+	assert.NoError(t, err)
+	assert.Equal(t, profile{URL: "http://other.local", Token: "override"}, settings)
+
+	// The token of a profile is never sent to another URL, and the other way around
+	_, err = resolveConnection("-config", path, "-profile", "lab", "-url", "http://other.local")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "set both -url and -token")
+
+	_, err = resolveConnection("-config", path, "-token", "override")
+	assert.Error(t, err)
+
+	_, err = resolveConnection("-config", path, "-profile", "staging")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "profile 'staging' is not defined")
+}
+
+func TestConfig_environment(t *testing.T) {
+	isolateConfig(t)
+	t.Setenv("NAGIOS_URL", "http://env.local/nagiosxi")
+	t.Setenv("API_TOKEN", "envtoken")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(testConfig), 0600))
+
+	// The environment wins over the default profile
+	settings, err := resolveConnection("-config", path)
+	assert.NoError(t, err)
+	assert.Equal(t, profile{URL: "http://env.local/nagiosxi", Token: "envtoken"}, settings)
+
+	// A profile that is asked for wins over the environment
+	t.Setenv("GONAGIOS_PROFILE", "lab")
+
+	settings, err = resolveConnection("-config", path)
+	assert.NoError(t, err)
+	assert.Equal(t, profile{URL: "https://nagios-lab.domain.local/nagiosxi", Token: "token456"}, settings)
+
+	// NAGIOS_URL without API_TOKEN does not borrow the token of the default profile
+	t.Setenv("GONAGIOS_PROFILE", "")
+	t.Setenv("API_TOKEN", "")
+
+	_, err = resolveConnection("-config", path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "set both NAGIOS_URL and API_TOKEN")
+
+	// The default configuration file is read from the user configuration directory
+	t.Setenv("GONAGIOS_PROFILE", "")
+	t.Setenv("NAGIOS_URL", "")
+	t.Setenv("API_TOKEN", "")
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(defaultConfigPath()), 0700))
+	assert.NoError(t, ioutil.WriteFile(defaultConfigPath(), []byte(testConfig), 0600))
+
+	settings, err = resolveConnection()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://nagios.domain.local/nagiosxi", settings.URL)
+}
+
+func TestConfig_invalidFile(t *testing.T) {
+	isolateConfig(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("profiles:\n  lab:\n    uri: http://typo\n"), 0600))
+
+	_, err := resolveConnection("-config", path)
+	assert.Error(t, err)
+
+	_, err = resolveConnection("-config", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// downtimeCommands are the subcommands of gonagios downtime
+var downtimeCommands = map[string]command{
+	"schedule": {"schedule downtime for hosts, services or groups", runDowntimeSchedule},
+	"list":     {"list scheduled downtime", runDowntimeList},
+	"delete":   {"cancel scheduled downtime by ID", runDowntimeDelete},
+}
+
+// timeLayouts are the formats accepted by -start and -end, in local time unless a zone is given
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"}
+
+// runDowntime runs a downtime subcommand
+func runDowntime(args []string, stdout, stderr io.Writer) int {
+	return runGroup("downtime", downtimeCommands, args, stdout, stderr)
+}
+
+// runDowntimeSchedule schedules downtime for the hosts in the arguments, or for their services when
+// -service is given, and for the groups given with -hostgroup and -servicegroup
+func runDowntimeSchedule(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("downtime schedule", "[host name...]", stderr)
+	conn := addConnectionFlags(flags)
+	comment := flags.String("comment", "", "why the downtime is scheduled (required)")
+	start := flags.String("start", "", "when the downtime starts, such as '2019-10-01 22:00', defaults to now")
+	end := flags.String("end", "", "when the downtime ends")
+	length := flags.Duration("for", 0, "how long the downtime lasts, instead of -end")
+	flexible := flags.Duration("flexible", 0, "schedule a flexible downtime of this length, which starts when the host or service goes down")
+
+	var services, hostGroups, serviceGroups stringList
+	flags.Var(&services, "service", "schedule downtime for this service of every host instead of the hosts, can be repeated")
+	flags.Var(&hostGroups, "hostgroup", "schedule downtime for the hosts of a host group, can be repeated")
+	flags.Var(&serviceGroups, "servicegroup", "schedule downtime for the services of a service group, can be repeated")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	downtime := &gonagios.Downtime{
+		HostGroups:    hostGroups,
+		ServiceGroups: serviceGroups,
+		Comment:       *comment,
+		Start:         time.Now(),
+		Flexible:      *flexible > 0,
+		Duration:      *flexible,
+	}
+
+	if len(services) > 0 {
+		downtime.Services = map[string][]string{}
+		for _, host := range flags.Args() {
+			downtime.Services[host] = services
+		}
+	} else {
+		downtime.Hosts = flags.Args()
+	}
+
+	var err error
+
+	if *start != "" {
+		if downtime.Start, err = parseTime(*start); err != nil {
+			return fail(stderr, err)
+		}
+	}
+
+	switch {
+	case *end != "" && *length != 0:
+		return fail(stderr, errors.New("set either -end or -for"))
+	case *end != "":
+		downtime.End, err = parseTime(*end)
+	case *length != 0:
+		downtime.End = downtime.Start.Add(*length)
+	}
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if err := client.ScheduleDowntime(downtime); err != nil {
+		return fail(stderr, err)
+	}
+
+	io.WriteString(stdout, "Scheduled downtime from "+downtime.Start.Format("2006-01-02 15:04")+" to "+downtime.End.Format("2006-01-02 15:04")+"\n")
+
+	return exitOK
+}
+
+// runDowntimeList prints every scheduled downtime
+func runDowntimeList(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("downtime list", "", stderr)
+	conn := addConnectionFlags(flags)
+	out := addOutputFlag(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if err := out.check(); err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	downtimes, err := client.ListDowntimes()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if downtimes == nil {
+		downtimes = []gonagios.ScheduledDowntime{}
+	}
+
+	err = out.write(stdout, downtimes, func(rows *table) {
+		rows.row("ID", "HOST", "SERVICE", "START", "END", "COMMENT")
+
+		for _, downtime := range downtimes {
+			rows.row(strconv.Itoa(downtime.ID), downtime.HostName, downtime.ServiceDescription, downtime.Start.Format("2006-01-02 15:04"), downtime.End.Format("2006-01-02 15:04"), downtime.Comment)
+		}
+	})
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// runDowntimeDelete cancels scheduled downtime
+func runDowntimeDelete(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("downtime delete", "<downtime ID>...", stderr)
+	conn := addConnectionFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	var ids []int
+
+	for _, arg := range flags.Args() {
+		id, err := strconv.Atoi(arg)
+
+		if err != nil {
+			return fail(stderr, errors.New("'"+arg+"' is not a downtime ID"))
+		}
+
+		ids = append(ids, id)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	for _, id := range ids {
+		if err := client.DeleteDowntime(id); err != nil {
+			return fail(stderr, err)
+		}
+
+		io.WriteString(stdout, "Deleted downtime "+strconv.Itoa(id)+"\n")
+	}
+
+	return exitOK
+}
+
+// parseTime reads a time given on the command line
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("'" + value + "' is not a time, use a format such as '2019-10-01 22:00'")
+}
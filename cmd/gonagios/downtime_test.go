@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDowntime_scheduleListDelete(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	code, stdout, stderr := runCommand("downtime", "schedule", "-comment", "patching", "-start", "2019-10-01 22:00", "-for", "2h", "web01")
+
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Scheduled downtime from 2019-10-01 22:00 to 2019-10-02 00:00\n", stdout)
+
+	code, _, stderr = runCommand("downtime", "schedule", "-comment", "deploy", "-start", "2019-10-01 22:00", "-end", "2019-10-01 23:00", "-service", "HTTP", "web01", "web02")
+
+	assert.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ = runCommand("downtime", "list")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "ID  HOST   SERVICE  START             END               COMMENT\n"+
+		"1   web01           2019-10-01 22:00  2019-10-02 00:00  patching\n"+
+		"2   web01  HTTP     2019-10-01 22:00  2019-10-01 23:00  deploy\n"+
+		"3   web02  HTTP     2019-10-01 22:00  2019-10-01 23:00  deploy\n", stdout)
+
+	code, stdout, _ = runCommand("downtime", "delete", "1", "3")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Deleted downtime 1\nDeleted downtime 3\n", stdout)
+	assert.Len(t, server.Records("scheduleddowntime"), 1)
+}
+
+func TestDowntime_invalid(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	code, _, stderr := runCommand("downtime", "schedule", "-for", "2h", "web01")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "comment: is required")
+
+	code, _, stderr = runCommand("downtime", "schedule", "-comment", "x", "-end", "2019-10-01 23:00", "-for", "2h", "web01")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "either -end or -for")
+
+	code, _, stderr = runCommand("downtime", "schedule", "-comment", "x", "-start", "tomorrow", "-for", "2h", "web01")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "'tomorrow' is not a time")
+
+	code, _, stderr = runCommand("downtime", "delete", "one")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "'one' is not a downtime ID")
+
+	assert.Empty(t, server.Records("scheduleddowntime"))
+}
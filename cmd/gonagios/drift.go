@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+
+	"github.com/devopsdunkin/gonagios/manifest"
+)
+
+// runDrift compares manifest files with XI and reports the differences
+func runDrift(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("drift", "<manifest file or directory>...", stderr)
+	conn := addConnectionFlags(flags)
+	out := addOutputFlag(flags)
+	format := flags.String("format", "", "deprecated, use -output")
+	exitCode := flags.Bool("exit-code", false, "exit with status 2 when drift is found")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	// -format text|json came before -output, and text is the table
+	if *format == "text" {
+		*out.format = outputTable
+	} else if *format != "" {
+		*out.format = *format
+	}
+
+	if err := out.check(); err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	m, err := manifest.Load(flags.Args()...)
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	drift, err := m.Drift(client)
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if *out.format == outputTable {
+		err = drift.WriteText(stdout)
+	} else {
+		err = out.write(stdout, drift, nil)
+	}
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if *exitCode && drift.Drifted() {
+		return exitDrift
+	}
+
+	return exitOK
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// hostCommands are the subcommands of gonagios hosts
+var hostCommands = map[string]command{
+	"get":    {"show every attribute of a host", runHostsGet},
+	"list":   {"list hosts", runHostsList},
+	"create": {"create a host from attribute=value arguments", runHostsCreate},
+	"update": {"change attributes of a host, attribute= clears one", runHostsUpdate},
+	"delete": {"delete hosts", runHostsDelete},
+}
+
+// runHosts runs a hosts subcommand
+func runHosts(args []string, stdout, stderr io.Writer) int {
+	return runGroup("hosts", hostCommands, args, stdout, stderr)
+}
+
+// runHostsGet prints a single host
+func runHostsGet(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("hosts get", "<host name>", stderr)
+	conn := addConnectionFlags(flags)
+	out := addOutputFlag(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	if err := out.check(); err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	host, err := client.GetHost(flags.Arg(0))
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	object, err := host.ConfigObject()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	err = out.write(stdout, object.Attributes, func(rows *table) {
+		rows.row("ATTRIBUTE", "VALUE")
+
+		for _, name := range sortedNames(object.Attributes) {
+			rows.row(name, object.Attributes[name])
+		}
+	})
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// runHostsList prints every host. Templates are left out
+func runHostsList(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("hosts list", "", stderr)
+	conn := addConnectionFlags(flags)
+	out := addOutputFlag(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	if err := out.check(); err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	objects, err := client.ListObjects(gonagios.ObjectHost)
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	hosts := []map[string]string{}
+
+	for i := range objects {
+		if objects[i].Names() != nil {
+			hosts = append(hosts, objects[i].Attributes)
+		}
+	}
+
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i]["host_name"] < hosts[j]["host_name"]
+	})
+
+	err = out.write(stdout, hosts, func(rows *table) {
+		rows.row("NAME", "ADDRESS", "ALIAS", "TEMPLATES")
+
+		for _, host := range hosts {
+			rows.row(host["host_name"], host["address"], host["alias"], host["use"])
+		}
+	})
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// runHostsCreate creates a host and applies the configuration
+func runHostsCreate(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("hosts create", "host_name=<name> <attribute>=<value>...", stderr)
+	conn := addConnectionFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	attributes, err := parseAttributes(flags.Args())
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if attributes["host_name"] == "" {
+		return fail(stderr, errors.New("host_name is required"))
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	object := &gonagios.ConfigObject{Type: gonagios.ObjectHost, Attributes: attributes}
+
+	session := client.NewConfigSession()
+
+	if err := session.NewObject(object); err != nil {
+		return fail(stderr, err)
+	}
+
+	if err := session.Commit(); err != nil {
+		return fail(stderr, err)
+	}
+
+	io.WriteString(stdout, "Created host "+object.Name()+"\n")
+
+	return exitOK
+}
+
+// runHostsUpdate changes attributes of a host and applies the configuration
+func runHostsUpdate(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("hosts update", "<host name> <attribute>=<value>...", stderr)
+	conn := addConnectionFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return exitError
+	}
+
+	attributes, err := parseAttributes(flags.Args()[1:])
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	if _, ok := attributes["host_name"]; ok {
+		return fail(stderr, errors.New("host_name cannot be changed with update"))
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	attributes["host_name"] = flags.Arg(0)
+
+	session := client.NewConfigSession()
+
+	if err := session.UpdateObject(&gonagios.ConfigObject{Type: gonagios.ObjectHost, Attributes: attributes}); err != nil {
+		return fail(stderr, err)
+	}
+
+	if err := session.Commit(); err != nil {
+		return fail(stderr, err)
+	}
+
+	io.WriteString(stdout, "Updated host "+flags.Arg(0)+"\n")
+
+	return exitOK
+}
+
+// runHostsDelete deletes hosts and applies the configuration once
+func runHostsDelete(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("hosts delete", "<host name>...", stderr)
+	conn := addConnectionFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	report, err := client.BulkDeleteHosts(flags.Args())
+
+	if report != nil {
+		for _, result := range report.Succeeded() {
+			io.WriteString(stdout, "Deleted host "+result.Name+"\n")
+		}
+	}
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// parseAttributes reads attribute=value arguments. An empty value is kept, so an update can clear the attribute
+func parseAttributes(args []string) (map[string]string, error) {
+	attributes := map[string]string{}
+
+	for _, arg := range args {
+		index := strings.Index(arg, "=")
+
+		if index <= 0 {
+			return nil, errors.New("'" + arg + "' is not an attribute=value argument")
+		}
+
+		attributes[arg[:index]] = arg[index+1:]
+	}
+
+	return attributes, nil
+}
+
+// sortedNames returns the keys of a map in order
+func sortedNames(attributes map[string]string) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
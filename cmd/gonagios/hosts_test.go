@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHosts_lifecycle(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	server.Add("host", map[string]string{"name": "generic-host", "register": "0"})
+
+	code, stdout, stderr := runCommand("hosts", "create", "host_name=web01", "address=10.0.0.1", "use=generic-host", "notes=web server")
+
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Created host web01\n", stdout)
+	assert.Equal(t, 1, server.Applies())
+
+	code, stdout, _ = runCommand("hosts", "list")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NAME   ADDRESS   ALIAS  TEMPLATES\nweb01  10.0.0.1         generic-host\n", stdout)
+
+	code, stdout, _ = runCommand("hosts", "update", "web01", "address=10.0.0.2", "notes=")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Updated host web01\n", stdout)
+
+	code, stdout, _ = runCommand("hosts", "get", "-output", "json", "web01")
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"address": "10.0.0.2"`)
+	assert.NotContains(t, stdout, "notes")
+
+	code, stdout, _ = runCommand("hosts", "get", "-output", "yaml", "web01")
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "host_name: web01\n")
+
+	code, stdout, _ = runCommand("hosts", "delete", "web01")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Deleted host web01\n", stdout)
+	assert.Nil(t, server.Find("host", "web01"))
+
+	code, _, stderr = runCommand("hosts", "get", "web01")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "does not exist")
+}
+
+func TestHosts_invalidArguments(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	code, _, stderr := runCommand("hosts", "create", "address=10.0.0.1")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "host_name is required")
+
+	code, _, stderr = runCommand("hosts", "create", "host_name")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "not an attribute=value argument")
+
+	code, _, stderr = runCommand("hosts", "update", "web01", "host_name=web02")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "host_name cannot be changed")
+
+	code, _, stderr = runCommand("hosts", "list", "-output", "xml")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "unknown output format")
+
+	assert.Equal(t, 0, server.Writes())
+}
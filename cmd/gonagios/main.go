@@ -0,0 +1,229 @@
+// Command gonagios manages a Nagios XI instance from the command line
+//
+// Usage:
+//
+//	gonagios <command> [flags] [arguments]
+//
+// The URL and API key of the XI instance are read from the -url and -token flags, the NAGIOS_URL and
+// API_TOKEN environment variables, or a profile in the configuration file selected with -profile:
+//
+//	default: production
+//	profiles:
+//	  production:
+//	    url: https://nagios.domain.local/nagiosxi
+//	    token: token123
+//
+// The configuration file is gonagios/config.yaml in the user configuration directory, such as
+// ~/.config/gonagios/config.yaml, unless -config or GONAGIOS_CONFIG name another one. Commands that print
+// objects take -output table, json or yaml
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// command is a subcommand of the CLI
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands are every subcommand of the CLI, by name
+var commands = map[string]command{
+	"ack":          {"acknowledge host and service problems", runAck},
+	"apply-config": {"apply the configuration and restart the Nagios core", runApplyConfig},
+	"downtime":     {"schedule, list and delete downtime", runDowntime},
+	"drift":        {"report differences between manifest files and XI", runDrift},
+	"hosts":        {"get, list, create, update and delete hosts", runHosts},
+	"status":       {"show the current state of hosts and services", runStatus},
+	"watch":        {"keep the current problems on screen, refreshed at an interval", runWatch},
+}
+
+// Exit codes shared by every command
+const (
+	exitOK    = 0
+	exitError = 1
+	// exitDrift is returned by commands asked to report differences through their exit code
+	exitDrift = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the subcommand named by the first argument and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+
+	if !ok {
+		fmt.Fprintf(stderr, "gonagios: unknown command '%s'\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+// usage lists every command
+func usage(w io.Writer) {
+	printCommands(w, "gonagios <command> [flags] [arguments]", commands)
+}
+
+// printCommands prints a usage line and the summary of every command
+func printCommands(w io.Writer, usageLine string, commands map[string]command) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: "+usageLine)
+	fmt.Fprintln(w, "\nCommands:")
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+}
+
+// runGroup runs a subcommand of a command that groups several, such as hosts list
+func runGroup(group string, subcommands map[string]command, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		printCommands(stderr, "gonagios "+group+" <command> [flags] [arguments]", subcommands)
+		return exitError
+	}
+
+	cmd, ok := subcommands[args[0]]
+
+	if !ok {
+		fmt.Fprintf(stderr, "gonagios: unknown command '%s %s'\n", group, args[0])
+		printCommands(stderr, "gonagios "+group+" <command> [flags] [arguments]", subcommands)
+		return exitError
+	}
+
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+// newFlagSet creates the flags of a command, with a usage message that shows its arguments
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		io.WriteString(stderr, "Usage: gonagios "+name+" [flags] "+arguments+"\n")
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// connection holds the flags that say how to reach XI
+type connection struct {
+	url     *string
+	token   *string
+	profile *string
+	config  *string
+}
+
+// addConnectionFlags adds the -url, -token, -profile and -config flags to a command
+func addConnectionFlags(flags *flag.FlagSet) *connection {
+	return &connection{
+		url:     flags.String("url", "", "URL of the Nagios XI instance (NAGIOS_URL)"),
+		token:   flags.String("token", "", "Nagios XI API key (API_TOKEN)"),
+		profile: flags.String("profile", os.Getenv("GONAGIOS_PROFILE"), "connection profile from the configuration file (GONAGIOS_PROFILE)"),
+		config:  flags.String("config", os.Getenv("GONAGIOS_CONFIG"), "configuration file (GONAGIOS_CONFIG), defaults to gonagios/config.yaml in the user config directory"),
+	}
+}
+
+// client creates a client from the connection flags
+func (conn *connection) client() (*gonagios.Client, error) {
+	settings, err := conn.resolve()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.URL == "" || settings.Token == "" {
+		return nil, errors.New("the XI URL and API key are required, set -url and -token, NAGIOS_URL and API_TOKEN, or a profile in the configuration file")
+	}
+
+	return gonagios.NewClient(strings.TrimSuffix(settings.URL, "/"), settings.Token), nil
+}
+
+// resolve works out the URL and API key. They are always taken together from one place, so an API key is
+// never sent to a URL it was not set up for. The flags come first, then a profile selected with -profile,
+// then the NAGIOS_URL and API_TOKEN environment variables, and last the default profile. The first place
+// that sets either of them has to set both
+func (conn *connection) resolve() (profile, error) {
+	path := *conn.config
+	required := path != "" || *conn.profile != ""
+
+	if path == "" {
+		path = defaultConfigPath()
+	}
+
+	cfg, err := loadConfig(path, required)
+
+	if err != nil {
+		return profile{}, err
+	}
+
+	selected, err := cfg.profile(*conn.profile, path)
+
+	if err != nil {
+		return profile{}, err
+	}
+
+	name := *conn.profile
+	if name == "" {
+		name = cfg.Default
+	}
+
+	flags := source{"-url and -token", profile{URL: *conn.url, Token: *conn.token}}
+	env := source{"NAGIOS_URL and API_TOKEN", profile{URL: os.Getenv("NAGIOS_URL"), Token: os.Getenv("API_TOKEN")}}
+	fromConfig := source{"the url and token of profile '" + name + "' in " + path, selected}
+
+	sources := []source{flags, env, fromConfig}
+	if *conn.profile != "" {
+		sources = []source{flags, fromConfig, env}
+	}
+
+	for _, source := range sources {
+		if source.settings.URL == "" && source.settings.Token == "" {
+			continue
+		}
+
+		if source.settings.URL == "" || source.settings.Token == "" {
+			return profile{}, errors.New("set both " + source.name + ", the URL and API key are never taken from different places")
+		}
+
+		return source.settings, nil
+	}
+
+	return profile{}, nil
+}
+
+// source is a place the URL and API key can be set
+type source struct {
+	name     string
+	settings profile
+}
+
+// fail reports an error and returns the error exit code
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintln(stderr, "gonagios:", err)
+
+	return exitError
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+// runCommand runs the CLI and returns its exit code and output
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+
+	code := run(args, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+// useServer points the CLI at a fake XI through the environment, without any configuration file
+func useServer(t *testing.T, server *fakenagios.Server) {
+	t.Setenv("NAGIOS_URL", server.URL)
+	t.Setenv("API_TOKEN", "token123")
+	isolateConfig(t)
+}
+
+// isolateConfig makes sure no configuration file or profile of the user running the tests is read
+func isolateConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GONAGIOS_CONFIG", "")
+	t.Setenv("GONAGIOS_PROFILE", "")
+}
+
+func TestMain_usage(t *testing.T) {
+	code, _, stderr := runCommand()
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "drift")
+
+	code, _, stderr = runCommand("unknown")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "unknown command 'unknown'")
+
+	code, _, stderr = runCommand("hosts")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "gonagios hosts <command>")
+
+	code, _, stderr = runCommand("hosts", "rename")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "unknown command 'hosts rename'")
+}
+
+func TestMain_connectionRequired(t *testing.T) {
+	t.Setenv("NAGIOS_URL", "")
+	t.Setenv("API_TOKEN", "")
+	isolateConfig(t)
+
+	code, _, stderr := runCommand("drift", "manifest.yaml")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "NAGIOS_URL")
+}
+
+func TestMain_errorsHideToken(t *testing.T) {
+	isolateConfig(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	// The connection is refused, and the error quotes the URL the API key is sent in
+	code, _, stderr := runCommand("hosts", "list", "-url", server.URL, "-token", "secret-token")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "apikey=REDACTED")
+	assert.NotContains(t, stderr, "secret-token")
+
+	// The URL cannot be parsed, and the parse error quotes it as well
+	for _, badURL := range []string{"http://nagios.local:abc/nagiosxi", "http://nagios.local/nagios%zz"} {
+		code, _, stderr = runCommand("hosts", "list", "-url", badURL, "-token", "secret-token")
+
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "apikey=REDACTED")
+		assert.NotContains(t, stderr, "secret-token")
+	}
+}
+
+func TestMain_drift(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "web01", "address": "10.0.0.9"})
+
+	path := filepath.Join(t.TempDir(), "hosts.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("hosts:\n  - host_name: web01\n    address: 10.0.0.1\n"), 0600))
+
+	code, stdout, _ := runCommand("drift", "-url", server.URL, "-token", "token123", path)
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "changed   host web01")
+
+	code, stdout, _ = runCommand("drift", "-url", server.URL, "-token", "token123", "-output", "json", "-exit-code", path)
+
+	assert.Equal(t, exitDrift, code)
+	assert.Contains(t, stdout, `"changed": [`)
+
+	code, stdout, _ = runCommand("drift", "-url", server.URL, "-token", "token123", "-output", "yaml", path)
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "changed:\n- fields:")
+
+	// -format is a deprecated alias of -output
+	code, stdout, _ = runCommand("drift", "-url", server.URL, "-token", "token123", "-format", "json", path)
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"changed": [`)
+
+	code, _, stderr := runCommand("drift", "-url", server.URL, "-token", "token123", "-output", "xml", path)
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "unknown output format")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// output holds the -output flag of a command that prints objects
+type output struct {
+	format *string
+}
+
+// addOutputFlag adds the -output flag to a command
+func addOutputFlag(flags *flag.FlagSet) *output {
+	return &output{
+		format: flags.String("output", outputTable, "output format, table, json or yaml"),
+	}
+}
+
+// check returns an error when the output format is unknown, so commands fail before they change anything
+func (out *output) check() error {
+	switch *out.format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+
+	return errors.New("unknown output format '" + *out.format + "', expected table, json or yaml")
+}
+
+// write prints value as JSON or YAML, or calls addRows to print a table
+// YAML uses the same field names as JSON
+func (out *output) write(w io.Writer, value interface{}, addRows func(*table)) error {
+	switch *out.format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case outputYAML:
+		data, err := json.Marshal(value)
+
+		if err != nil {
+			return err
+		}
+
+		var document interface{}
+
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return err
+		}
+
+		data, err = yaml.Marshal(document)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(data)
+
+		return err
+	}
+
+	rows := &table{writer: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}
+	addRows(rows)
+
+	return rows.writer.Flush()
+}
+
+// table lines up rows of cells in columns
+type table struct {
+	writer *tabwriter.Writer
+}
+
+// row adds a row, replacing the tabs and line breaks in the cells that would break the columns
+func (rows *table) row(cells ...string) {
+	for i := range cells {
+		cells[i] = strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(cells[i])
+	}
+
+	fmt.Fprintln(rows.writer, strings.Join(cells, "\t"))
+}
+
+// stringList is a flag that can be given several times
+type stringList []string
+
+// String returns the values joined with commas
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+// Set adds a value
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+
+	return nil
+}
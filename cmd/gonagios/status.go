@@ -0,0 +1,178 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/devopsdunkin/gonagios/watch"
+)
+
+// statusCommands are the subcommands of gonagios status
+var statusCommands = map[string]command{
+	"hosts":    {"show the state of hosts", runStatusHosts},
+	"services": {"show the state of services", runStatusServices},
+}
+
+// runStatus runs a status subcommand
+func runStatus(args []string, stdout, stderr io.Writer) int {
+	return runGroup("status", statusCommands, args, stdout, stderr)
+}
+
+// runStatusHosts prints the state of every host, or of the hosts named in the arguments
+func runStatusHosts(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("status hosts", "[host name...]", stderr)
+	conn := addConnectionFlags(flags)
+	out := addOutputFlag(flags)
+	problems := flags.Bool("problems", false, "only show hosts that are not up")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if err := out.check(); err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	var statuses []gonagios.HostStatus
+
+	if flags.NArg() == 0 {
+		statuses, err = client.ListHostStatus()
+	}
+
+	for _, name := range flags.Args() {
+		var status *gonagios.HostStatus
+
+		status, err = client.GetHostStatus(name)
+
+		if err != nil {
+			break
+		}
+
+		statuses = append(statuses, *status)
+	}
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	shown := []gonagios.HostStatus{}
+
+	for _, status := range statuses {
+		if !*problems || status.Problem() {
+			shown = append(shown, status)
+		}
+	}
+
+	now := time.Now()
+
+	err = out.write(stdout, shown, func(rows *table) {
+		rows.row("HOST", "STATE", "SINCE", "FLAGS", "OUTPUT")
+
+		for i := range shown {
+			status := &shown[i]
+			rows.row(status.HostName, status.CurrentState.String(), watch.Age(status.LastStateChange, now), statusFlags(&status.CheckStatus), status.Output)
+		}
+	})
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// runStatusServices prints the state of every service, or of the services of the hosts named in the arguments
+func runStatusServices(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("status services", "[host name...]", stderr)
+	conn := addConnectionFlags(flags)
+	out := addOutputFlag(flags)
+	problems := flags.Bool("problems", false, "only show services that are not OK")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if err := out.check(); err != nil {
+		return fail(stderr, err)
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	hosts := flags.Args()
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+
+	shown := []gonagios.ServiceStatus{}
+
+	for _, host := range hosts {
+		statuses, err := client.ListServiceStatus(host)
+
+		if err != nil {
+			return fail(stderr, err)
+		}
+
+		for _, status := range statuses {
+			if !*problems || status.Problem() {
+				shown = append(shown, status)
+			}
+		}
+	}
+
+	now := time.Now()
+
+	err = out.write(stdout, shown, func(rows *table) {
+		rows.row("HOST", "SERVICE", "STATE", "SINCE", "FLAGS", "OUTPUT")
+
+		for i := range shown {
+			status := &shown[i]
+			rows.row(status.HostName, status.ServiceDescription, status.CurrentState.String(), watch.Age(status.LastStateChange, now), statusFlags(&status.CheckStatus), status.Output)
+		}
+	})
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// statusFlags summarizes what is special about a state: ack when the problem is acknowledged, downtime,
+// flapping and soft when the state is not confirmed yet
+func statusFlags(status *gonagios.CheckStatus) string {
+	var flags []string
+
+	if status.ProblemAcknowledged {
+		flags = append(flags, "ack")
+	}
+
+	if status.InDowntime() {
+		flags = append(flags, "downtime")
+	}
+
+	if status.IsFlapping {
+		flags = append(flags, "flapping")
+	}
+
+	if status.HasBeenChecked && !status.HardState() {
+		flags = append(flags, "soft")
+	}
+
+	if len(flags) == 0 {
+		return "-"
+	}
+
+	return strings.Join(flags, ",")
+}
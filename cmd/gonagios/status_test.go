@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus_hosts(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	server.AddRecord("hoststatus", map[string]string{"host_name": "web01", "current_state": "1", "state_type": "1", "has_been_checked": "1", "problem_acknowledged": "1", "output": "PING CRITICAL"})
+	server.AddRecord("hoststatus", map[string]string{"host_name": "web02", "current_state": "0", "state_type": "1", "has_been_checked": "1", "output": "PING OK"})
+
+	code, stdout, _ := runCommand("status", "hosts")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "HOST   STATE  SINCE  FLAGS  OUTPUT\nweb01  DOWN   -      ack    PING CRITICAL\nweb02  UP     -      -      PING OK\n", stdout)
+
+	code, stdout, _ = runCommand("status", "hosts", "-problems", "-output", "json")
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"host_name": "web01"`)
+	assert.NotContains(t, stdout, "web02")
+
+	code, stdout, _ = runCommand("status", "hosts", "web02")
+
+	assert.Equal(t, exitOK, code)
+	assert.NotContains(t, stdout, "web01")
+
+	code, _, stderr := runCommand("status", "hosts", "web03")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "web03")
+}
+
+func TestStatus_services(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "HTTP", "current_state": "2", "state_type": "0", "has_been_checked": "1", "output": "Connection refused"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web02", "service_description": "HTTP", "current_state": "0"})
+
+	code, stdout, _ := runCommand("status", "services", "-problems")
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "HOST   SERVICE  STATE     SINCE  FLAGS  OUTPUT\nweb01  HTTP     CRITICAL  -      soft   Connection refused\n", stdout)
+
+	code, stdout, _ = runCommand("status", "services", "-output", "yaml", "web02")
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "  service_description: HTTP\n")
+	assert.NotContains(t, stdout, "web01")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/devopsdunkin/gonagios/watch"
+)
+
+// runWatch redraws the current problems at an interval until interrupted
+func runWatch(args []string, stdout, stderr io.Writer) int {
+	flags := newFlagSet("watch", "", stderr)
+	conn := addConnectionFlags(flags)
+	interval := flags.Duration("interval", watch.DefaultInterval, "how often to poll")
+	once := flags.Bool("once", false, "print the problems once and exit, without clearing the terminal")
+	noColor := flags.Bool("no-color", os.Getenv("NO_COLOR") != "", "do not use colors (NO_COLOR), they are only used on a terminal")
+	width := flags.Int("width", terminalWidth(), "cut lines to this many characters, 0 for no limit (COLUMNS)")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	client, err := conn.client()
+
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	watcher := &watch.Watcher{
+		Client:   client,
+		Interval: *interval,
+		Output:   stdout,
+		Options: watch.RenderOptions{
+			Clear: !*once,
+			Color: !*noColor && isTerminal(stdout),
+			Width: *width,
+		},
+	}
+
+	if *once {
+		snapshot, err := watch.Collect(client)
+
+		if err != nil {
+			return fail(stderr, err)
+		}
+
+		if err := watch.Render(stdout, snapshot, watch.Compare(nil, snapshot), watcher.Options); err != nil {
+			return fail(stderr, err)
+		}
+
+		return exitOK
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+
+	go func() {
+		select {
+		case <-interrupted:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := watcher.Run(ctx); err != nil {
+		return fail(stderr, err)
+	}
+
+	return exitOK
+}
+
+// terminalWidth reads the width of the terminal from COLUMNS, or returns 0 when it is not set
+func terminalWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+
+	if err != nil || width < 0 {
+		return 0
+	}
+
+	return width
+}
+
+// isTerminal returns true when output goes to a terminal rather than a file or a pipe
+func isTerminal(output io.Writer) bool {
+	file, ok := output.(*os.File)
+
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
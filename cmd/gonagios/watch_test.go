@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch_once(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	useServer(t, server)
+
+	server.AddRecord("hoststatus", map[string]string{"host_name": "web01", "current_state": "0"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "HTTP", "current_state": "2", "output": "refused"})
+
+	code, stdout, stderr := runCommand("watch", "-once", "-no-color", "-width", "0")
+
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "1 problems, 0 new, 0 recovered")
+	assert.Contains(t, stdout, "CRITICAL  web01  HTTP")
+	assert.NotContains(t, stdout, "\x1b[")
+
+	// Colors are left out when the output is not a terminal, even without -no-color
+	t.Setenv("NO_COLOR", "")
+
+	code, stdout, stderr = runCommand("watch", "-once", "-width", "0")
+
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "CRITICAL  web01  HTTP")
+	assert.NotContains(t, stdout, "\x1b[")
+}
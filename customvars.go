@@ -0,0 +1,116 @@
+package gonagios
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Custom object variables (free variables) are attributes starting with an underscore, such as _SNMP_COMMUNITY
+// Nagios treats their names as case-insensitive and converts them to upper case, so we do the same
+
+// normalizeVarName converts a custom variable name to the upper case, underscore prefixed form Nagios uses
+func normalizeVarName(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	if !strings.HasPrefix(name, "_") {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// isCustomVariable returns true for attribute names that are custom variables
+func isCustomVariable(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// SetVar sets a custom variable on the host. The name is converted to upper case and prefixed with an
+// underscore if needed, so SetVar("snmp_community", "public") sets _SNMP_COMMUNITY
+func (host *Host) SetVar(name, value string) {
+	normalized := normalizeVarName(name)
+
+	if host.FreeVariables == nil {
+		host.FreeVariables = map[string]interface{}{}
+	}
+
+	// Remove any variable that only differs in case, so it cannot be sent twice
+	for existing := range host.FreeVariables {
+		if normalizeVarName(existing) == normalized {
+			delete(host.FreeVariables, existing)
+		}
+	}
+
+	host.FreeVariables[normalized] = value
+}
+
+// GetVar returns the value of a custom variable of the host. The name is matched case-insensitively
+// and the leading underscore is optional
+func (host *Host) GetVar(name string) (string, bool) {
+	normalized := normalizeVarName(name)
+
+	for existing, value := range host.FreeVariables {
+		if normalizeVarName(existing) == normalized {
+			return stringifyJSON(value), true
+		}
+	}
+
+	return "", false
+}
+
+// SetVar sets a custom variable on the host, see Host.SetVar
+func (typed *TypedHost) SetVar(name, value string) {
+	normalized := normalizeVarName(name)
+
+	if typed.FreeVariables == nil {
+		typed.FreeVariables = map[string]string{}
+	}
+
+	for existing := range typed.FreeVariables {
+		if normalizeVarName(existing) == normalized {
+			delete(typed.FreeVariables, existing)
+		}
+	}
+
+	typed.FreeVariables[normalized] = value
+}
+
+// GetVar returns the value of a custom variable of the host, see Host.GetVar
+func (typed *TypedHost) GetVar(name string) (string, bool) {
+	normalized := normalizeVarName(name)
+
+	for existing, value := range typed.FreeVariables {
+		if normalizeVarName(existing) == normalized {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// variables checks the names and values of custom variables
+// Names must not contain whitespace or be empty, must not clash once converted to upper case,
+// and values must be plain strings, numbers or booleans
+func (check *validator) variables(field string, variables map[string]interface{}) {
+	seen := map[string]string{}
+
+	for name, value := range variables {
+		normalized := normalizeVarName(name)
+
+		if normalized == "_" || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+			check.add(field, "'"+name+"' is not a valid custom variable name")
+			continue
+		}
+
+		if other, ok := seen[normalized]; ok {
+			check.add(field, "'"+name+"' and '"+other+"' are the same custom variable")
+		}
+
+		seen[normalized] = name
+
+		switch value.(type) {
+		case string, bool, int, int64, float64:
+		default:
+			check.add(field, "value of '"+name+"' must be a string, number or boolean")
+		}
+	}
+}
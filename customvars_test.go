@@ -0,0 +1,67 @@
+package gonagios
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomVars_getHostExtractsVariables(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"host_name":"host1","address":"127.0.0.1","_snmp_community":"public","_PORT":161,"id":"42"}]`))
+	})
+	defer server.Close()
+
+	host, err := client.GetHost("host1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"_SNMP_COMMUNITY": "public", "_PORT": "161"}, host.FreeVariables)
+
+	value, ok := host.GetVar("Snmp_Community")
+
+	assert.True(t, ok)
+	assert.Equal(t, "public", value)
+}
+
+func TestCustomVars_setVar(t *testing.T) {
+	host := createHostObject()
+	host.FreeVariables = map[string]interface{}{"_location": "dc1", "_RACK": 12, "_ENABLED": true}
+
+	host.SetVar("LOCATION", "dc2")
+
+	value, ok := host.GetVar("_Location")
+
+	assert.True(t, ok)
+	assert.Equal(t, "dc2", value)
+	assert.NoError(t, host.Validate())
+
+	params, err := encodeValues(host)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "dc2", params.Get("_LOCATION"))
+	assert.Equal(t, "12", params.Get("_RACK"))
+	assert.Equal(t, "1", params.Get("_ENABLED"))
+	assert.NotContains(t, params, "_location")
+
+	_, ok = host.GetVar("missing")
+
+	assert.False(t, ok)
+}
+
+func TestCustomVars_validation(t *testing.T) {
+	host := createHostObject()
+	host.FreeVariables = map[string]interface{}{
+		"_SITE":   "dc1",
+		"_site":   "dc2",
+		"bad var": "x",
+		"_LIST":   []string{"a"},
+	}
+
+	err := host.Validate()
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "'bad var' is not a valid custom variable name")
+	assert.Contains(t, err.Error(), "are the same custom variable")
+	assert.Contains(t, err.Error(), "value of '_LIST' must be a string, number or boolean")
+}
@@ -0,0 +1,110 @@
+package gonagios
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+)
+
+// FieldChange is a single attribute that differs between the current and desired version of an object
+// An empty New means the attribute is cleared
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// DiffHost returns the attributes that have to change to turn current into desired
+// Empty fields in desired are left alone, only the attributes named in clearFields are removed.
+// A custom variable is cleared by its name, such as "_LOCATION"
+func DiffHost(current, desired *Host, clearFields ...string) ([]FieldChange, error) {
+	clearFields, err := hostClearFields(clearFields)
+
+	if err != nil {
+		return nil, err
+	}
+
+	currentValues, err := encodeValues(current)
+
+	if err != nil {
+		return nil, err
+	}
+
+	desiredValues, err := encodeValues(desired, clearFields...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return diffValues(currentValues, desiredValues), nil
+}
+
+// diffValues compares every attribute of desired with the same attribute of current
+// Attributes that are only in current are not changed
+func diffValues(current, desired url.Values) []FieldChange {
+	var changes []FieldChange
+
+	for field := range desired {
+		if desired.Get(field) != current.Get(field) {
+			changes = append(changes, FieldChange{
+				Field: field,
+				Old:   current.Get(field),
+				New:   desired.Get(field),
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// removedFields returns the attributes set in current that are missing from desired
+func removedFields(current, desired url.Values) []string {
+	var fields []string
+
+	for field := range current {
+		if _, ok := desired[field]; !ok && current.Get(field) != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
+
+// changeValues converts a list of changes to the URL parameters that make them
+func changeValues(changes []FieldChange) url.Values {
+	values := url.Values{}
+
+	for _, change := range changes {
+		values.Set(change.Field, change.New)
+	}
+
+	return values
+}
+
+// hostClearFields checks the names of the attributes to clear from a host
+// Custom variable names are normalized, anything else has to be an attribute of Host
+func hostClearFields(fields []string) ([]string, error) {
+	known := attributeNames(Host{})
+	normalized := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		switch {
+		case isCustomVariable(field):
+			field = normalizeVarName(field)
+		case field == "host_name":
+			return nil, errors.New("host_name cannot be cleared")
+		case !known[field]:
+			return nil, errors.New("'" + field + "' is not a host attribute")
+		}
+
+		normalized = append(normalized, field)
+	}
+
+	return normalized, nil
+}
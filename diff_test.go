@@ -0,0 +1,121 @@
+package gonagios
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff_diffHost(t *testing.T) {
+	current := &Host{HostName: "host1", Address: "127.0.0.1", NotesURL: "http://wiki/host1", Contacts: []interface{}{"nagiosadmin"}}
+	desired := &Host{Address: "127.0.0.2", Contacts: []interface{}{"nagiosadmin"}}
+
+	changes, err := DiffHost(current, desired, "notes_url", "_location")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Field: "address", Old: "127.0.0.1", New: "127.0.0.2"},
+		{Field: "notes_url", Old: "http://wiki/host1", New: ""},
+	}, changes)
+}
+
+func TestDiff_diffHostRejectsBadClears(t *testing.T) {
+	current := &Host{HostName: "host1"}
+
+	_, err := DiffHost(current, &Host{}, "not_an_attribute")
+	assert.Error(t, err)
+
+	_, err = DiffHost(current, &Host{}, "host_name")
+	assert.Error(t, err)
+
+	_, err = DiffHost(current, &Host{NotesURL: "http://wiki/host1"}, "notes_url")
+	assert.Error(t, err)
+}
+
+func TestDiff_patchHost(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1", "notes_url": "http://wiki/host1", "_LOCATION": "dc1"})
+
+	client := NewClient(server.URL, "token123")
+
+	changed, err := client.PatchHost("host1", &Host{Address: "127.0.0.2"}, "notes_url", "_location")
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	host := server.Find("host", "host1")
+	assert.Equal(t, "127.0.0.2", host["address"])
+	assert.NotContains(t, host, "notes_url")
+	assert.NotContains(t, host, "_LOCATION")
+	assert.Equal(t, 1, server.Writes())
+	assert.Equal(t, 1, server.Applies())
+}
+
+func TestDiff_patchHostUnchanged(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+
+	client := NewClient(server.URL, "token123")
+
+	changed, err := client.PatchHost("host1", &Host{Address: "127.0.0.1"}, "notes_url")
+
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 0, server.Writes())
+	assert.Equal(t, 0, server.Applies())
+}
+
+func TestDiff_sessionPatchHost(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+	server.Add("host", map[string]string{"host_name": "host2", "address": "127.0.0.2"})
+
+	client := NewClient(server.URL, "token123")
+	session := client.NewConfigSession()
+
+	changed, err := session.PatchHost("host1", &Host{Address: "127.0.0.1"})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = session.PatchHost("host2", &Host{Address: "10.0.0.2"})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	_, err = session.PatchHost("missing", &Host{Address: "10.0.0.3"})
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	assert.Len(t, session.Results(), 2)
+	assert.Error(t, session.Commit())
+	assert.Equal(t, 1, server.Applies())
+}
+
+func TestDiff_restoreClearsAddedFields(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+	server.Add("host", map[string]string{"host_name": "host2", "address": "127.0.0.2"})
+
+	client := NewClient(server.URL, "token123")
+
+	snapshot, err := client.Snapshot(hostObjects("host1", "host2")...)
+	assert.NoError(t, err)
+
+	_, err = client.PatchHost("host1", &Host{NotesURL: "http://wiki/host1"})
+	assert.NoError(t, err)
+
+	writes := server.Writes()
+
+	assert.NoError(t, client.Restore(snapshot))
+	assert.NotContains(t, server.Find("host", "host1"), "notes_url")
+	// host2 did not change, so only host1 is written
+	assert.Equal(t, writes+1, server.Writes())
+}
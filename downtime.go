@@ -0,0 +1,150 @@
+package gonagios
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Downtime schedules downtime for hosts, services and groups, see ScheduleDowntime
+// At least one host, service or group is required, and a comment, start and end
+type Downtime struct {
+	Hosts []string
+	// Services holds service descriptions by host name
+	Services      map[string][]string
+	HostGroups    []string
+	ServiceGroups []string
+	Comment       string
+	Start         time.Time
+	End           time.Time
+	// A flexible downtime starts when the object goes down between Start and End, and lasts for Duration
+	Flexible bool
+	Duration time.Duration
+}
+
+// ScheduledDowntime is a downtime known to Nagios, as reported by objects/downtime
+type ScheduledDowntime struct {
+	ID                 int           `json:"internal_id" nagios:"internal_id"`
+	HostName           string        `json:"host_name" nagios:"host_name"`
+	ServiceDescription string        `json:"service_description,omitempty" nagios:"service_description"`
+	Author             string        `json:"author_name" nagios:"author_name"`
+	Comment            string        `json:"comment_data" nagios:"comment_data"`
+	EntryTime          time.Time     `json:"entry_time" nagios:"entry_time"`
+	Start              time.Time     `json:"scheduled_start_time" nagios:"scheduled_start_time"`
+	End                time.Time     `json:"scheduled_end_time" nagios:"scheduled_end_time"`
+	Duration           time.Duration `json:"duration" nagios:"duration"`
+	Fixed              bool          `json:"is_fixed" nagios:"is_fixed"`
+	InEffect           bool          `json:"is_in_effect" nagios:"is_in_effect"`
+}
+
+// values validates the downtime and converts it to the parameters of system/scheduleddowntime
+func (downtime *Downtime) values() (url.Values, error) {
+	check := &validator{}
+
+	if len(downtime.Hosts) == 0 && len(downtime.Services) == 0 && len(downtime.HostGroups) == 0 && len(downtime.ServiceGroups) == 0 {
+		check.add("hosts", "at least one host, service, host group or service group is required")
+	}
+
+	check.required("comment", downtime.Comment)
+
+	if downtime.Start.IsZero() {
+		check.add("start", "is required")
+	}
+
+	if downtime.End.IsZero() {
+		check.add("end", "is required")
+	} else if !downtime.End.After(downtime.Start) {
+		check.add("end", "must be after the start")
+	}
+
+	if downtime.Flexible && downtime.Duration < time.Minute {
+		check.add("duration", "a flexible downtime needs a duration of at least a minute")
+	}
+
+	if err := check.result("downtime", downtime.Comment); err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("comment", downtime.Comment)
+	values.Set("start", strconv.FormatInt(downtime.Start.Unix(), 10))
+	values.Set("end", strconv.FormatInt(downtime.End.Unix(), 10))
+
+	if downtime.Flexible {
+		values.Set("flexible", "1")
+		values.Set("duration", strconv.Itoa(int(downtime.Duration/time.Minute)))
+	}
+
+	for _, host := range downtime.Hosts {
+		values.Add("hosts[]", host)
+	}
+
+	hostNames := make([]string, 0, len(downtime.Services))
+	for host := range downtime.Services {
+		hostNames = append(hostNames, host)
+	}
+
+	sort.Strings(hostNames)
+
+	for _, host := range hostNames {
+		for _, service := range downtime.Services[host] {
+			values.Add("services["+host+"][]", service)
+		}
+	}
+
+	for _, group := range downtime.HostGroups {
+		values.Add("hostgroups[]", group)
+	}
+
+	for _, group := range downtime.ServiceGroups {
+		values.Add("servicegroups[]", group)
+	}
+
+	return values, nil
+}
+
+// ScheduleDowntime schedules downtime in Nagios
+// The downtime is checked before it is sent, every problem is returned at once in a *ValidationError
+func (client *Client) ScheduleDowntime(downtime *Downtime) error {
+	values, err := downtime.values()
+
+	if err != nil {
+		return err
+	}
+
+	nagiosURL := client.buildURL("system", "scheduleddowntime", http.MethodPost)
+
+	_, err = client.post(&values, nagiosURL)
+
+	return err
+}
+
+// ListDowntimes retrieves every scheduled downtime
+func (client *Client) ListDowntimes() ([]ScheduledDowntime, error) {
+	var downtimes []ScheduledDowntime
+
+	err := client.getRecords("downtime", "scheduleddowntime", url.Values{}, func(attributes map[string]interface{}) error {
+		downtime := ScheduledDowntime{}
+		err := decodeValues(attributes, &downtime)
+		downtimes = append(downtimes, downtime)
+		return err
+	})
+
+	return downtimes, err
+}
+
+// DeleteDowntime cancels a scheduled downtime by its ID, see ScheduledDowntime.ID
+func (client *Client) DeleteDowntime(id int) error {
+	if id <= 0 {
+		return errors.New("invalid downtime ID " + strconv.Itoa(id))
+	}
+
+	nagiosURL := client.buildURL("system", "scheduleddowntime", http.MethodDelete, strconv.Itoa(id))
+
+	_, err := client.delete(&url.Values{}, nagiosURL)
+
+	return err
+}
@@ -0,0 +1,89 @@
+package gonagios
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDowntime_scheduleListDelete(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+	start := time.Date(2019, 10, 1, 22, 0, 0, 0, time.UTC)
+
+	err := client.ScheduleDowntime(&Downtime{
+		Hosts:    []string{"web01"},
+		Services: map[string][]string{"web02": {"HTTP", "PING"}},
+		Comment:  "patching",
+		Start:    start,
+		End:      start.Add(2 * time.Hour),
+	})
+	assert.NoError(t, err)
+
+	downtimes, err := client.ListDowntimes()
+
+	assert.NoError(t, err)
+	assert.Len(t, downtimes, 3)
+	assert.Equal(t, 1, downtimes[0].ID)
+	assert.Equal(t, "web01", downtimes[0].HostName)
+	assert.Equal(t, "", downtimes[0].ServiceDescription)
+	assert.Equal(t, "HTTP", downtimes[1].ServiceDescription)
+	assert.Equal(t, "patching", downtimes[0].Comment)
+	assert.True(t, downtimes[0].Fixed)
+	assert.True(t, downtimes[0].Start.Equal(start))
+	assert.True(t, downtimes[0].End.Equal(start.Add(2*time.Hour)))
+
+	assert.NoError(t, client.DeleteDowntime(2))
+
+	downtimes, err = client.ListDowntimes()
+	assert.NoError(t, err)
+	assert.Len(t, downtimes, 2)
+
+	assert.Error(t, client.DeleteDowntime(2))
+	assert.Error(t, client.DeleteDowntime(0))
+}
+
+func TestDowntime_flexible(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+	start := time.Now()
+
+	err := client.ScheduleDowntime(&Downtime{
+		HostGroups: []string{"linux"},
+		Hosts:      []string{"web01"},
+		Comment:    "maybe",
+		Start:      start,
+		End:        start.Add(time.Hour),
+		Flexible:   true,
+		Duration:   30 * time.Minute,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "0", server.Records("scheduleddowntime")[0]["is_fixed"])
+}
+
+func TestDowntime_validation(t *testing.T) {
+	client := NewClient("http://localhost", "token123")
+	start := time.Now()
+
+	err := client.ScheduleDowntime(&Downtime{Start: start, End: start.Add(-time.Hour), Flexible: true})
+
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	var validationError *ValidationError
+	assert.True(t, errors.As(err, &validationError))
+
+	var fields []string
+	for _, problem := range validationError.Problems {
+		fields = append(fields, problem.Field)
+	}
+
+	assert.Equal(t, []string{"comment", "duration", "end", "hosts"}, fields)
+}
@@ -0,0 +1,428 @@
+package gonagios
+
+import (
+	"encoding"
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nagios objects are encoded to and decoded from URL parameters using the `nagios` struct tag:
+//
+//	HostName      string                 `nagios:"host_name"`
+//	ActiveChecks  *bool                  `nagios:"active_checks_enabled"`
+//	RetryInterval *time.Duration         `nagios:"retry_interval,interval"`
+//	FreeVariables map[string]interface{} `nagios:",vars"`
+//
+// Zero values are not sent, so a field is only set when it holds a value. Use a pointer to send a zero
+// value such as false or 0: a nil pointer is unset and a non-nil pointer is always sent. Slices are joined
+// with commas, booleans are sent as 1 or 0, durations as seconds, times in the XI timestamp format, and types implementing
+// encoding.TextMarshaler (such as the option sets) as their text. Durations tagged with the interval option are
+// counted in Nagios interval units instead, see intervalUnit. A map tagged with the vars option holds
+// custom variables. Nested structs without a tag are flattened into the same set of parameters.
+// Fields without a tag, or tagged with "-", are ignored
+
+// nagiosTag is the struct tag read by the encoder and decoder
+const nagiosTag = "nagios"
+
+// intervalUnit is implemented by objects that set the length of the interval units their intervals are
+// counted in. The intervals of other objects are counted in DefaultIntervalLength units
+type intervalUnit interface {
+	intervalLength() time.Duration
+}
+
+// intervalLengthOf returns the length of the interval units of an object
+func intervalLengthOf(object interface{}) time.Duration {
+	if unit, ok := object.(intervalUnit); ok {
+		return unit.intervalLength()
+	}
+
+	return DefaultIntervalLength
+}
+
+// encodeValues encodes a struct, or a pointer to one, into URL parameters
+// Every attribute named in clear is sent with an empty value, which removes it from the object in Nagios
+func encodeValues(object interface{}, clear ...string) (url.Values, error) {
+	values := url.Values{}
+
+	value := reflect.ValueOf(object)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return values, nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, errors.New("cannot encode " + value.Type().String() + ", expected a struct")
+	}
+
+	if err := encodeStruct(value, values, intervalLengthOf(object)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range clear {
+		if values.Get(name) != "" {
+			return nil, errors.New("cannot both set and clear '" + name + "'")
+		}
+		values.Set(name, "")
+	}
+
+	return values, nil
+}
+
+// encodeStruct adds every tagged field of the struct to the URL parameters
+// Durations tagged with the interval option are counted in units of intervalLength
+func encodeStruct(value reflect.Value, values url.Values, intervalLength time.Duration) error {
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		field := value.Field(i)
+
+		name, options, tagged := parseNagiosTag(structField)
+
+		if !tagged {
+			if structField.Type.Kind() == reflect.Struct && (structField.Anonymous || structField.PkgPath == "") {
+				if err := encodeStruct(field, values, intervalLength); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		if options["vars"] {
+			if err := encodeVariables(field, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A nil pointer is unset, while a pointer to a zero value is sent
+		explicit := false
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+			explicit = true
+		}
+
+		if !explicit && isUnset(field) {
+			continue
+		}
+
+		encoded, err := encodeField(field, fieldUnit(options, intervalLength))
+
+		if err != nil {
+			return errors.New(name + ": " + err.Error())
+		}
+
+		values.Set(name, encoded)
+	}
+
+	return nil
+}
+
+// fieldUnit returns the unit the durations of a field are counted in: intervals for a field tagged with the
+// interval option, and seconds otherwise
+func fieldUnit(options map[string]bool, intervalLength time.Duration) time.Duration {
+	if options["interval"] {
+		return intervalLength
+	}
+
+	return time.Second
+}
+
+// encodeField converts a single value to the string Nagios expects, with durations as a number of units
+func encodeField(field reflect.Value, unit time.Duration) (string, error) {
+	if field.Type() == timeType {
+		return field.Interface().(time.Time).Format(programTimeLayout), nil
+	}
+
+	if field.Type().Implements(textMarshalerType) {
+		text, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	if field.Type() == durationType {
+		return strconv.FormatFloat(float64(field.Int())/float64(unit), 'f', -1, 64), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return convertBoolToIntToString(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 64), nil
+	case reflect.Interface:
+		if field.IsNil() {
+			return "", nil
+		}
+		return encodeField(field.Elem(), unit)
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			item, err := encodeField(field.Index(i), unit)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	return "", errors.New("unsupported type " + field.Type().String())
+}
+
+// encodeVariables adds the custom variables held in a map to the URL parameters
+func encodeVariables(field reflect.Value, values url.Values) error {
+	if field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String {
+		return errors.New("vars field must be a map with string keys")
+	}
+
+	variables := field.MapRange()
+
+	for variables.Next() {
+		encoded, err := encodeField(variables.Value(), time.Second)
+
+		if err != nil {
+			return errors.New(variables.Key().String() + ": " + err.Error())
+		}
+
+		values.Set(normalizeVarName(variables.Key().String()), encoded)
+	}
+
+	return nil
+}
+
+// decodeValues sets the tagged fields of the struct pointed to by object from the attributes of a Nagios object
+// The attributes can hold strings, numbers, booleans or lists as returned by XI. Every invalid value is reported at once
+func decodeValues(attributes map[string]interface{}, object interface{}) error {
+	value := reflect.ValueOf(object)
+
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return errors.New("cannot decode into " + value.Type().String() + ", expected a pointer to a struct")
+	}
+
+	var problems []string
+
+	decodeStruct(attributes, value.Elem(), intervalLengthOf(object), &problems)
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New("invalid attributes: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// decodeStruct sets every tagged field of the struct that has a matching attribute
+// Durations tagged with the interval option are counted in units of intervalLength
+func decodeStruct(attributes map[string]interface{}, value reflect.Value, intervalLength time.Duration, problems *[]string) {
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		field := value.Field(i)
+
+		name, options, tagged := parseNagiosTag(structField)
+
+		if !tagged {
+			if structField.Type.Kind() == reflect.Struct && (structField.Anonymous || structField.PkgPath == "") {
+				decodeStruct(attributes, field, intervalLength, problems)
+			}
+			continue
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		if options["vars"] {
+			if err := decodeVariables(attributes, field); err != nil {
+				*problems = append(*problems, err.Error())
+			}
+			continue
+		}
+
+		raw, ok := attributes[name]
+		if !ok || raw == nil {
+			continue
+		}
+
+		if err := decodeField(stringifyJSON(raw), field, fieldUnit(options, intervalLength)); err != nil {
+			*problems = append(*problems, name+": "+err.Error())
+		}
+	}
+}
+
+// decodeField parses the string form of an attribute into a field
+// Durations may be a number of units or a duration string such as "10m"
+func decodeField(text string, field reflect.Value, unit time.Duration) error {
+	if field.Kind() == reflect.Ptr {
+		target := reflect.New(field.Type().Elem())
+
+		if err := decodeField(text, target.Elem(), unit); err != nil {
+			return err
+		}
+
+		field.Set(target)
+
+		return nil
+	}
+
+	if field.Type() == timeType {
+		timestamp, err := parseTime(text)
+		field.Set(reflect.ValueOf(timestamp))
+		return err
+	}
+
+	if reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	if field.Type() == durationType {
+		duration, err := parseDuration(text, unit)
+		field.SetInt(int64(duration))
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(text)
+	case reflect.Bool:
+		enabled, err := parseBool(text)
+		if err != nil {
+			return err
+		}
+		field.SetBool(enabled)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := parseInt(text)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return errors.New("'" + text + "' is not a positive integer")
+		}
+		field.SetUint(number)
+	case reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return errors.New("'" + text + "' is not a number")
+		}
+		field.SetFloat(number)
+	case reflect.Interface:
+		field.Set(reflect.ValueOf(text))
+	case reflect.Slice:
+		items := splitList(text)
+		list := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeField(item, list.Index(i), unit); err != nil {
+				return err
+			}
+		}
+		field.Set(list)
+	default:
+		return errors.New("unsupported type " + field.Type().String())
+	}
+
+	return nil
+}
+
+// decodeVariables fills a map tagged with the vars option with the custom variables found in the attributes
+func decodeVariables(attributes map[string]interface{}, field reflect.Value) error {
+	if field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String {
+		return errors.New("vars field must be a map with string keys")
+	}
+
+	variables := reflect.MakeMap(field.Type())
+
+	for name, raw := range attributes {
+		if !isCustomVariable(name) {
+			continue
+		}
+
+		value := reflect.New(field.Type().Elem()).Elem()
+
+		if err := decodeField(stringifyJSON(raw), value, time.Second); err != nil {
+			return errors.New(name + ": " + err.Error())
+		}
+
+		variables.SetMapIndex(reflect.ValueOf(normalizeVarName(name)), value)
+	}
+
+	if variables.Len() > 0 {
+		field.Set(variables)
+	}
+
+	return nil
+}
+
+// parseNagiosTag returns the attribute name and options of a field, and whether it has a nagios tag at all
+func parseNagiosTag(field reflect.StructField) (string, map[string]bool, bool) {
+	tag, ok := field.Tag.Lookup(nagiosTag)
+
+	if !ok {
+		return "", nil, false
+	}
+
+	parts := strings.Split(tag, ",")
+	options := map[string]bool{}
+
+	for _, option := range parts[1:] {
+		options[option] = true
+	}
+
+	return parts[0], options, true
+}
+
+// attributeNames returns the name of every attribute a struct type is encoded with, not counting custom variables
+func attributeNames(object interface{}) map[string]bool {
+	names := map[string]bool{}
+
+	valueType := reflect.TypeOf(object)
+	for valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+
+	addAttributeNames(valueType, names)
+
+	return names
+}
+
+// addAttributeNames adds the attribute names of every tagged field of a struct type
+func addAttributeNames(valueType reflect.Type, names map[string]bool) {
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+
+		name, options, tagged := parseNagiosTag(structField)
+
+		if !tagged {
+			if structField.Type.Kind() == reflect.Struct && (structField.Anonymous || structField.PkgPath == "") {
+				addAttributeNames(structField.Type, names)
+			}
+			continue
+		}
+
+		if name != "-" && !options["vars"] {
+			names[name] = true
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package gonagios
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type encoderCheck struct {
+	Interval time.Duration `nagios:"check_interval"`
+	Attempts int           `nagios:"max_check_attempts"`
+}
+
+type encoderObject struct {
+	Name      string                  `nagios:"host_name"`
+	Enabled   *bool                   `nagios:"active_checks_enabled"`
+	Retain    bool                    `nagios:"retain_status_information"`
+	Timeout   *int                    `nagios:"check_timeout"`
+	Threshold float64                 `nagios:"low_flap_threshold"`
+	Parents   []string                `nagios:"parents"`
+	Options   HostNotificationOptions `nagios:"notification_options"`
+	Ignored   string
+	Skipped   string            `nagios:"-"`
+	Variables map[string]string `nagios:",vars"`
+	encoderCheck
+}
+
+type encoderIntervals struct {
+	Retry     *time.Duration `nagios:"retry_interval,interval"`
+	Freshness time.Duration  `nagios:"freshness_threshold"`
+	length    time.Duration
+}
+
+func (object *encoderIntervals) intervalLength() time.Duration {
+	return object.length
+}
+
+func TestEncoder_encodeValues(t *testing.T) {
+	disabled := false
+	zero := 0
+
+	object := encoderObject{
+		Name:      "host1",
+		Enabled:   &disabled,
+		Timeout:   &zero,
+		Threshold: 12.5,
+		Parents:   []string{"router1", "router2"},
+		Options:   HostNotifyDown | HostNotifyRecovery,
+		Ignored:   "ignored",
+		Skipped:   "skipped",
+		Variables: map[string]string{"snmp_community": "public"},
+		encoderCheck: encoderCheck{
+			Interval: 90 * time.Second,
+			Attempts: 3,
+		},
+	}
+
+	values, err := encodeValues(&object)
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"host_name":             {"host1"},
+		"active_checks_enabled": {"0"},
+		"check_timeout":         {"0"},
+		"low_flap_threshold":    {"12.5"},
+		"parents":               {"router1,router2"},
+		"notification_options":  {"d,r"},
+		"_SNMP_COMMUNITY":       {"public"},
+		"check_interval":        {"90"},
+		"max_check_attempts":    {"3"},
+	}, values)
+}
+
+func TestEncoder_encodeValuesSkipsUnset(t *testing.T) {
+	values, err := encodeValues(encoderObject{Name: "host1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"host_name": {"host1"}}, values)
+}
+
+func TestEncoder_encodeValuesClear(t *testing.T) {
+	values, err := encodeValues(encoderObject{Name: "host1"}, "parents")
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"host_name": {"host1"}, "parents": {""}}, values)
+
+	_, err = encodeValues(encoderObject{Name: "host1"}, "host_name")
+
+	assert.Error(t, err)
+}
+
+func TestEncoder_encodeValuesRejectsNonStruct(t *testing.T) {
+	_, err := encodeValues("host1")
+
+	assert.Error(t, err)
+}
+
+func TestEncoder_encodeHostLists(t *testing.T) {
+	host := &Host{
+		HostName:             "host1",
+		Contacts:             []interface{}{"nagiosadmin", "oncall"},
+		FlapDetectionOptions: []interface{}{"o", "d"},
+	}
+
+	values, err := encodeValues(host)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "nagiosadmin,oncall", values.Get("contacts"))
+	assert.Equal(t, "o,d", values.Get("flap_detection_options"))
+	assert.NotContains(t, values, "parents")
+	assert.NotContains(t, values, "contact_groups")
+}
+
+func TestEncoder_decodeValues(t *testing.T) {
+	attributes := map[string]interface{}{
+		"host_name":             "host1",
+		"active_checks_enabled": "0",
+		"check_timeout":         float64(30),
+		"low_flap_threshold":    "12.5",
+		"parents":               []interface{}{"router1", "router2"},
+		"notification_options":  "d,r",
+		"check_interval":        "90",
+		"max_check_attempts":    "3",
+		"_snmp_community":       "public",
+		"Ignored":               "not decoded",
+	}
+
+	var object encoderObject
+
+	err := decodeValues(attributes, &object)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "host1", object.Name)
+	assert.Equal(t, false, *object.Enabled)
+	assert.Equal(t, 30, *object.Timeout)
+	assert.Equal(t, 12.5, object.Threshold)
+	assert.Equal(t, []string{"router1", "router2"}, object.Parents)
+	assert.Equal(t, HostNotifyDown|HostNotifyRecovery, object.Options)
+	assert.Equal(t, 90*time.Second, object.Interval)
+	assert.Equal(t, 3, object.Attempts)
+	assert.Equal(t, map[string]string{"_SNMP_COMMUNITY": "public"}, object.Variables)
+	assert.Equal(t, "", object.Ignored)
+}
+
+func TestEncoder_decodeValuesReportsEveryProblem(t *testing.T) {
+	attributes := map[string]interface{}{
+		"active_checks_enabled": "maybe",
+		"max_check_attempts":    "three",
+	}
+
+	var object encoderObject
+
+	err := decodeValues(attributes, &object)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "active_checks_enabled")
+	assert.Contains(t, err.Error(), "max_check_attempts")
+}
+
+func TestEncoder_decodeTimes(t *testing.T) {
+	var object struct {
+		Changed time.Time  `nagios:"last_state_change"`
+		Checked time.Time  `nagios:"last_check"`
+		Started *time.Time `nagios:"program_start"`
+		Never   time.Time  `nagios:"last_notification"`
+		Invalid time.Time  `nagios:"next_check"`
+	}
+
+	err := decodeValues(map[string]interface{}{
+		"last_state_change": "2019-10-01 10:00:00",
+		"last_check":        float64(1569924000),
+		"program_start":     "2019-10-01T10:00:00Z",
+		"last_notification": "0000-00-00 00:00:00",
+		"next_check":        "soon",
+	}, &object)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "next_check")
+	assert.Equal(t, time.Date(2019, 10, 1, 10, 0, 0, 0, time.Local), object.Changed)
+	assert.True(t, object.Checked.Equal(time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)))
+	assert.True(t, object.Started.Equal(time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)))
+	assert.True(t, object.Never.IsZero())
+}
+
+func TestEncoder_roundTrip(t *testing.T) {
+	host := createHostObject()
+	host.SetVar("location", "dc1")
+
+	values, err := encodeValues(host)
+
+	assert.NoError(t, err)
+
+	attributes := map[string]interface{}{}
+	for key := range values {
+		attributes[key] = values.Get(key)
+	}
+
+	decoded := &Host{}
+
+	assert.NoError(t, decodeValues(attributes, decoded))
+
+	again, err := encodeValues(decoded)
+
+	assert.NoError(t, err)
+	assert.Equal(t, values, again)
+}
+
+func TestEncoder_intervals(t *testing.T) {
+	object := &encoderIntervals{Retry: Duration(90 * time.Second), Freshness: 90 * time.Second, length: 30 * time.Second}
+
+	values, err := encodeValues(object)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3", values.Get("retry_interval"))
+	assert.Equal(t, "90", values.Get("freshness_threshold"))
+
+	decoded := &encoderIntervals{length: 30 * time.Second}
+
+	assert.NoError(t, decodeValues(map[string]interface{}{"retry_interval": "2", "freshness_threshold": "2"}, decoded))
+	assert.Equal(t, time.Minute, *decoded.Retry)
+	assert.Equal(t, 2*time.Second, decoded.Freshness)
+
+	// Objects that do not set their interval length count intervals in DefaultIntervalLength units
+	var plain struct {
+		Retry time.Duration `nagios:"retry_interval,interval"`
+	}
+
+	assert.NoError(t, decodeValues(map[string]interface{}{"retry_interval": "2"}, &plain))
+	assert.Equal(t, 2*DefaultIntervalLength, plain.Retry)
+}
@@ -0,0 +1,87 @@
+package gonagios
+
+import (
+	"errors"
+)
+
+// EnsureResult is what EnsureHost had to do to make Nagios match the host
+type EnsureResult int
+
+// Results returned by EnsureHost
+const (
+	EnsureUnchanged EnsureResult = iota
+	EnsureCreated
+	EnsureUpdated
+)
+
+// String returns the name of the result, such as "created"
+func (result EnsureResult) String() string {
+	switch result {
+	case EnsureCreated:
+		return "created"
+	case EnsureUpdated:
+		return "updated"
+	case EnsureUnchanged:
+		return "unchanged"
+	}
+
+	return "unknown"
+}
+
+// EnsureHost makes sure a host exists in Nagios with the given attributes, so it is safe to call repeatedly
+// The host is created when it does not exist and patched when it differs, see PatchHost. The configuration
+// is only applied when something changed
+func (client *Client) EnsureHost(host *Host) (EnsureResult, error) {
+	result, err := client.ensureHost(host)
+
+	if err != nil || result == EnsureUnchanged {
+		return result, err
+	}
+
+	err = client.applyConfig()
+
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// ensureHost creates or patches a host without applying the configuration
+func (client *Client) ensureHost(host *Host) (EnsureResult, error) {
+	// GetHost returns every host when the name is empty, so it has to be checked first
+	check := &validator{}
+	check.required("host_name", host.HostName)
+
+	if err := check.result(objectType, host.HostName); err != nil {
+		return EnsureUnchanged, err
+	}
+
+	current, err := client.GetHost(host.HostName)
+
+	if errors.Is(err, ErrNotFound) {
+		_, err = client.createHost(host, false)
+
+		if err != nil {
+			return EnsureUnchanged, err
+		}
+
+		return EnsureCreated, nil
+	}
+
+	if err != nil {
+		return EnsureUnchanged, err
+	}
+
+	if err := validateHost(host, false); err != nil {
+		return EnsureUnchanged, err
+	}
+
+	fieldChanges, err := client.patchCurrentHost(current, host)
+
+	if err != nil || len(fieldChanges) == 0 {
+		return EnsureUnchanged, err
+	}
+
+	return EnsureUpdated, nil
+}
@@ -0,0 +1,55 @@
+package gonagios
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsure_ensureHost(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+	host := createNamedHostObject("host1")
+
+	result, err := client.EnsureHost(host)
+	assert.NoError(t, err)
+	assert.Equal(t, EnsureCreated, result)
+
+	result, err = client.EnsureHost(host)
+	assert.NoError(t, err)
+	assert.Equal(t, EnsureUnchanged, result)
+
+	host.Address = "10.0.0.1"
+
+	result, err = client.EnsureHost(host)
+	assert.NoError(t, err)
+	assert.Equal(t, EnsureUpdated, result)
+
+	assert.Len(t, server.Objects("host"), 1)
+	assert.Equal(t, "10.0.0.1", server.Find("host", "host1")["address"])
+	assert.Equal(t, 2, server.Writes())
+	assert.Equal(t, 2, server.Applies())
+}
+
+func TestEnsure_ensureHostRequiresName(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+
+	_, err := client.EnsureHost(&Host{Address: "127.0.0.1"})
+
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Equal(t, 0, server.Writes())
+}
+
+func TestEnsure_resultString(t *testing.T) {
+	assert.Equal(t, "created", EnsureCreated.String())
+	assert.Equal(t, "updated", EnsureUpdated.String())
+	assert.Equal(t, "unchanged", EnsureUnchanged.String())
+	assert.Equal(t, "unknown", EnsureResult(42).String())
+}
@@ -0,0 +1,183 @@
+package gonagios
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors that describe why a request failed
+// An *APIError wraps one of them, so callers can branch with errors.Is instead of matching strings
+var (
+	ErrNotFound     = errors.New("object not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrValidation   = errors.New("validation failed")
+	// ErrUnsupported means the XI version does not have the endpoint, such as the config endpoints of
+	// dependencies and escalations on older versions
+	ErrUnsupported = errors.New("unsupported by this Nagios XI version")
+	// ErrUnexpectedResponse means Nagios answered with something other than a JSON API response,
+	// such as the HTML login page or PHP error output
+	ErrUnexpectedResponse = errors.New("unexpected response")
+	// ErrTooManyDeletions is returned when a prune would delete more objects than allowed, before anything is deleted
+	ErrTooManyDeletions = errors.New("too many deletions")
+)
+
+// maxBodySnippet is how much of an unexpected response body is kept in an APIError
+const maxBodySnippet = 512
+
+// APIError is returned when Nagios XI rejects a request
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Message    string
+	Messages   []string
+	Missing    []string
+	// ContentType and Body are only set when the response was not valid JSON
+	// Body holds a truncated snippet of what Nagios sent back
+	ContentType string
+	Body        string
+	// Kind is one of the sentinel errors, or nil when the failure could not be classified
+	Kind error
+}
+
+// Error formats the API error including any missing fields and messages returned by Nagios
+func (apiError *APIError) Error() string {
+	var message strings.Builder
+
+	message.WriteString("nagios: " + apiError.Method + " " + apiError.Endpoint)
+
+	if apiError.StatusCode != 0 && apiError.StatusCode != http.StatusOK {
+		message.WriteString(" (" + http.StatusText(apiError.StatusCode) + ")")
+	}
+
+	message.WriteString(": " + apiError.Message)
+
+	if len(apiError.Missing) > 0 {
+		message.WriteString(" [missing: " + strings.Join(apiError.Missing, ", ") + "]")
+	}
+
+	if len(apiError.Messages) > 0 {
+		message.WriteString(" [" + strings.Join(apiError.Messages, "; ") + "]")
+	}
+
+	if apiError.Body != "" {
+		message.WriteString(": " + strconv.Quote(apiError.Body))
+	}
+
+	return message.String()
+}
+
+// Unwrap returns the sentinel error describing the kind of failure
+func (apiError *APIError) Unwrap() error {
+	return apiError.Kind
+}
+
+// newAPIError builds an APIError from a HTTP response and the response code Nagios returned
+func newAPIError(response *http.Response, responseCode *ResponseCode) *APIError {
+	apiError := &APIError{
+		StatusCode: response.StatusCode,
+		Method:     response.Request.Method,
+		Endpoint:   response.Request.URL.Path,
+		Message:    responseCode.ReponseError,
+		Messages:   responseCode.Messages,
+		Missing:    responseCode.Missing,
+	}
+
+	if apiError.Message == "" {
+		apiError.Message = http.StatusText(response.StatusCode)
+	}
+
+	apiError.Kind = classifyAPIError(apiError)
+
+	return apiError
+}
+
+// unexpectedResponseError builds the error returned when Nagios does not answer with a JSON API response
+func unexpectedResponseError(response *http.Response, body []byte, reason string) *APIError {
+	apiError := &APIError{
+		StatusCode:  response.StatusCode,
+		Method:      response.Request.Method,
+		Endpoint:    response.Request.URL.Path,
+		Message:     reason,
+		ContentType: response.Header.Get("Content-Type"),
+		Body:        bodySnippet(body),
+	}
+
+	// Keep the status code classification (a 401 is still unauthorized) but fall back to an unexpected response
+	apiError.Kind = classifyStatusCode(response.StatusCode)
+
+	if apiError.Kind == nil {
+		apiError.Kind = ErrUnexpectedResponse
+	}
+
+	return apiError
+}
+
+// bodySnippet collapses whitespace and truncates the body so it can be included in an error message
+func bodySnippet(body []byte) string {
+	truncated := len(body) > maxBodySnippet
+
+	if truncated {
+		body = body[:maxBodySnippet]
+	}
+
+	// Dropping invalid UTF-8 also removes a multi-byte character we may have cut in half
+	snippet := strings.ToValidUTF8(strings.Join(strings.Fields(string(body)), " "), "")
+
+	if truncated {
+		snippet += "..."
+	}
+
+	return snippet
+}
+
+// notFoundError builds the error returned when a lookup does not match any object
+func notFoundError(objectType, name string) *APIError {
+	return &APIError{
+		StatusCode: http.StatusOK,
+		Method:     http.MethodGet,
+		Endpoint:   objectType,
+		Message:    objectType + " '" + name + "' does not exist",
+		Kind:       ErrNotFound,
+	}
+}
+
+// classifyStatusCode determines the kind of failure from the HTTP status code alone
+func classifyStatusCode(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	}
+
+	return nil
+}
+
+// classifyAPIError determines the kind of failure
+// XI answers most failures with a HTTP 200 and an error message, so the message is checked as well as the status code
+func classifyAPIError(apiError *APIError) error {
+	if kind := classifyStatusCode(apiError.StatusCode); kind != nil {
+		return kind
+	}
+
+	message := strings.ToLower(apiError.Message)
+
+	switch {
+	case strings.Contains(message, "api key"), strings.Contains(message, "not authorized"):
+		return ErrUnauthorized
+	case strings.Contains(message, "unknown api endpoint"):
+		return ErrUnsupported
+	case strings.Contains(message, "does not exist"), strings.Contains(message, "could not find"),
+		strings.Contains(message, "not found"):
+		return ErrNotFound
+	case len(apiError.Missing) > 0, strings.Contains(message, "missing"), strings.Contains(message, "invalid"):
+		return ErrValidation
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package gonagios
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError_validation(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"Missing required variables","missing":["address","check_period"],"messages":{"host_name":"Too long"}}`))
+	})
+	defer server.Close()
+
+	_, err := client.NewHost(createHostObject())
+
+	var apiError *APIError
+	assert.True(t, errors.As(err, &apiError))
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Equal(t, []string{"address", "check_period"}, apiError.Missing)
+	assert.Equal(t, []string{"host_name: Too long"}, apiError.Messages)
+	assert.Equal(t, "/api/v1/config/host", apiError.Endpoint)
+	assert.NotContains(t, err.Error(), "token123")
+}
+
+func TestAPIError_messagesSorted(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"Invalid values","messages":{"max_check_attempts":"Must be a number","address":"Required","host_name":"Too long","check_period":"Unknown"}}`))
+	})
+	defer server.Close()
+
+	for i := 0; i < 10; i++ {
+		_, err := client.NewHost(createHostObject())
+
+		var apiError *APIError
+		assert.True(t, errors.As(err, &apiError))
+		assert.Equal(t, []string{"address: Required", "check_period: Unknown", "host_name: Too long", "max_check_attempts: Must be a number"}, apiError.Messages)
+	}
+}
+
+func TestAPIError_unauthorized(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"Invalid API Key"}`))
+	})
+	defer server.Close()
+
+	_, err := client.GetHost("host1")
+
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
+
+func TestAPIError_notFound(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	defer server.Close()
+
+	host, err := client.GetHost("host1")
+
+	assert.Nil(t, host)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestAPIError_unsupported(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"Unknown API endpoint."}`))
+	})
+	defer server.Close()
+
+	_, err := client.ListObjects(ObjectHostEscalation)
+
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestAPIError_statusCode(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{}`))
+	})
+	defer server.Close()
+
+	_, err := client.DeleteHost("host1")
+
+	var apiError *APIError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestAPIError_unexpectedHTML(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>\n  <title>Login &middot; Nagios XI</title>\n</html>"))
+	})
+	defer server.Close()
+
+	_, err := client.GetHost("host1")
+
+	var apiError *APIError
+	assert.True(t, errors.As(err, &apiError))
+	assert.True(t, errors.Is(err, ErrUnexpectedResponse))
+	assert.Equal(t, "text/html", apiError.ContentType)
+	assert.Equal(t, "<html> <title>Login &middot; Nagios XI</title> </html>", apiError.Body)
+}
+
+func TestAPIError_bodySnippetTruncated(t *testing.T) {
+	body := make([]byte, 2*maxBodySnippet)
+	for i := range body {
+		body[i] = 'x'
+	}
+
+	snippet := bodySnippet(body)
+
+	assert.Len(t, snippet, maxBodySnippet+3)
+	assert.Equal(t, "short", bodySnippet([]byte("short")))
+}
@@ -10,59 +10,57 @@ var apiType = "config"
 var objectType = "host"
 
 // Host contains all available attributes for a Nagios host object
+// The nagios tags name the attribute each field is sent as, see encodeValues
 type Host struct {
-	HostName                   string                 `json:"host_name"`
-	Address                    string                 `json:"address"`
-	DisplayName                string                 `json:"display_name,omitempty"`
-	MaxCheckAttempts           string                 `json:"max_check_attempts"`
-	CheckPeriod                string                 `json:"check_period"`
-	NotificationInterval       string                 `json:"notification_interval"`
-	NotificationPeriod         string                 `json:"notification_period"`
-	Contacts                   []interface{}          `json:"contacts"`
-	Alias                      string                 `json:"alias,omitempty"`
-	Templates                  []interface{}          `json:"use,omitempty"`
-	CheckCommand               string                 `json:"check_command,omitempty"`
-	ContactGroups              []interface{}          `json:"contact_groups,omitempty"`
-	Notes                      string                 `json:"notes,omitempty"`
-	NotesURL                   string                 `json:"notes_url,omitempty"`
-	ActionURL                  string                 `json:"action_url,omitempty"`
-	InitialState               string                 `json:"initial_state,omitempty"`
-	RetryInterval              string                 `json:"retry_interval,omitempty"`
-	PassiveChecksEnabled       string                 `json:"passive_checks_enabled,omitempty"`
-	ActiveChecksEnabled        string                 `json:"active_checks_enabled,omitempty"`
-	ObsessOverHost             string                 `json:"obsess_over_host,omitempty"`
-	EventHandler               string                 `json:"event_handler,omitempty"`
-	EventHandlerEnabled        string                 `json:"event_handler_enabled,omitempty"`
-	FlapDetectionEnabled       string                 `json:"flap_detection_enabled,omitempty"`
-	FlapDetectionOptions       []interface{}          `json:"flap_detection_options,omitempty"`
-	LowFlapThreshold           string                 `json:"low_flap_threshold,omitempty"`
-	HighFlapThreshold          string                 `json:"high_flap_threshold,omitempty"`
-	ProcessPerfData            string                 `json:"process_perf_data,omitempty"`
-	RetainStatusInformation    string                 `json:"retain_status_information,omitempty"`
-	RetainNonstatusInformation string                 `json:"retain_nonstatus_information,omitempty"`
-	CheckFreshness             string                 `json:"check_freshness,omitempty"`
-	FreshnessThreshold         string                 `json:"freshness_threshold,omitempty"`
-	FirstNotificationDelay     string                 `json:"first_notification_delay,omitempty"`
-	NotificationOptions        string                 `json:"notification_options,omitempty"`
-	NotificationsEnabled       string                 `json:"notifications_enabled,omitempty"`
-	StalkingOptions            string                 `json:"stalking_options,omitempty"`
-	IconImage                  string                 `json:"icon_image,omitempty"`
-	IconImageAlt               string                 `json:"icon_image_alt,omitempty"`
-	VRMLImage                  string                 `json:"vrml_image,omitempty"`
-	StatusMapImage             string                 `json:"statusmap_image,omitempty"`
-	TwoDCoords                 string                 `json:"2d_coords,omitempty"`
-	ThreeDCoords               string                 `json:"3d_coords,omitempty"`
-	Register                   string                 `json:"register,omitempty"`
-	FreeVariables              map[string]interface{} `json:"free_variables,omitempty"`
+	HostName                   string                 `json:"host_name" nagios:"host_name"`
+	Address                    string                 `json:"address" nagios:"address"`
+	DisplayName                string                 `json:"display_name,omitempty" nagios:"display_name"`
+	MaxCheckAttempts           string                 `json:"max_check_attempts" nagios:"max_check_attempts"`
+	CheckPeriod                string                 `json:"check_period" nagios:"check_period"`
+	NotificationInterval       string                 `json:"notification_interval" nagios:"notification_interval"`
+	NotificationPeriod         string                 `json:"notification_period" nagios:"notification_period"`
+	Contacts                   []interface{}          `json:"contacts" nagios:"contacts"`
+	Alias                      string                 `json:"alias,omitempty" nagios:"alias"`
+	Templates                  []interface{}          `json:"use,omitempty" nagios:"use"`
+	CheckCommand               string                 `json:"check_command,omitempty" nagios:"check_command"`
+	ContactGroups              []interface{}          `json:"contact_groups,omitempty" nagios:"contact_groups"`
+	Notes                      string                 `json:"notes,omitempty" nagios:"notes"`
+	NotesURL                   string                 `json:"notes_url,omitempty" nagios:"notes_url"`
+	ActionURL                  string                 `json:"action_url,omitempty" nagios:"action_url"`
+	InitialState               string                 `json:"initial_state,omitempty" nagios:"initial_state"`
+	RetryInterval              string                 `json:"retry_interval,omitempty" nagios:"retry_interval"`
+	PassiveChecksEnabled       string                 `json:"passive_checks_enabled,omitempty" nagios:"passive_checks_enabled"`
+	ActiveChecksEnabled        string                 `json:"active_checks_enabled,omitempty" nagios:"active_checks_enabled"`
+	ObsessOverHost             string                 `json:"obsess_over_host,omitempty" nagios:"obsess_over_host"`
+	EventHandler               string                 `json:"event_handler,omitempty" nagios:"event_handler"`
+	EventHandlerEnabled        string                 `json:"event_handler_enabled,omitempty" nagios:"event_handler_enabled"`
+	FlapDetectionEnabled       string                 `json:"flap_detection_enabled,omitempty" nagios:"flap_detection_enabled"`
+	FlapDetectionOptions       []interface{}          `json:"flap_detection_options,omitempty" nagios:"flap_detection_options"`
+	LowFlapThreshold           string                 `json:"low_flap_threshold,omitempty" nagios:"low_flap_threshold"`
+	HighFlapThreshold          string                 `json:"high_flap_threshold,omitempty" nagios:"high_flap_threshold"`
+	ProcessPerfData            string                 `json:"process_perf_data,omitempty" nagios:"process_perf_data"`
+	RetainStatusInformation    string                 `json:"retain_status_information,omitempty" nagios:"retain_status_information"`
+	RetainNonstatusInformation string                 `json:"retain_nonstatus_information,omitempty" nagios:"retain_nonstatus_information"`
+	CheckFreshness             string                 `json:"check_freshness,omitempty" nagios:"check_freshness"`
+	FreshnessThreshold         string                 `json:"freshness_threshold,omitempty" nagios:"freshness_threshold"`
+	FirstNotificationDelay     string                 `json:"first_notification_delay,omitempty" nagios:"first_notification_delay"`
+	NotificationOptions        string                 `json:"notification_options,omitempty" nagios:"notification_options"`
+	NotificationsEnabled       string                 `json:"notifications_enabled,omitempty" nagios:"notifications_enabled"`
+	StalkingOptions            string                 `json:"stalking_options,omitempty" nagios:"stalking_options"`
+	IconImage                  string                 `json:"icon_image,omitempty" nagios:"icon_image"`
+	IconImageAlt               string                 `json:"icon_image_alt,omitempty" nagios:"icon_image_alt"`
+	VRMLImage                  string                 `json:"vrml_image,omitempty" nagios:"vrml_image"`
+	StatusMapImage             string                 `json:"statusmap_image,omitempty" nagios:"statusmap_image"`
+	TwoDCoords                 string                 `json:"2d_coords,omitempty" nagios:"2d_coords"`
+	ThreeDCoords               string                 `json:"3d_coords,omitempty" nagios:"3d_coords"`
+	Register                   string                 `json:"register,omitempty" nagios:"register"`
+	FreeVariables              map[string]interface{} `json:"free_variables,omitempty" nagios:",vars"`
 }
 
 // NewHost creates a host object in Nagios XI
+// The host is validated before it is sent, see Host.Validate
 func (client *Client) NewHost(host *Host) ([]byte, error) {
-	nagiosURL := client.buildURL(apiType, objectType, http.MethodPost)
-
-	data := setURLParams(host)
-
-	body, err := client.post(data, nagiosURL)
+	body, err := client.createHost(host, false)
 
 	if err != nil {
 		return nil, err
@@ -77,10 +75,41 @@ func (client *Client) NewHost(host *Host) ([]byte, error) {
 	return body, nil
 }
 
+// createHost creates a host object in Nagios XI without applying the configuration
+// When force is set the host is not validated and XI is told to accept it without the attributes it
+// normally requires, which is needed to recreate hosts that inherit those attributes from templates.
+// Otherwise the host is marked with the client's ownership marker, if it has one
+func (client *Client) createHost(host *Host, force bool) ([]byte, error) {
+	if !force {
+		if err := validateHost(host, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := client.snapshotBefore(OperationCreate, hostObjects(host.HostName)...); err != nil {
+		return nil, err
+	}
+
+	nagiosURL := client.buildURL(apiType, objectType, http.MethodPost)
+
+	data, err := encodeValues(host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// A forced create restores a host exactly as it was, so it is not marked as owned
+	if force {
+		data.Set("force", "1")
+	} else {
+		client.Owner.mark(data)
+	}
+
+	return client.post(&data, nagiosURL)
+}
+
 // GetHost retrieves an existing host from Nagios
 func (client *Client) GetHost(name string) (*Host, error) {
-	var hostArray = []Host{}
-
 	nagiosURL := client.buildURL(apiType, objectType, http.MethodGet)
 
 	// Iniitialize but we aren't setting anything since htis is a HTTP GET
@@ -89,7 +118,7 @@ func (client *Client) GetHost(name string) (*Host, error) {
 
 	// Append '&host_name=' and the name var to the end of the URL
 	// Nagios will return all hosts unless we pass a URL query parameter filtering the results
-	nagiosURL = nagiosURL + "&host_name=" + name
+	nagiosURL = addQueryParams(nagiosURL, url.Values{"host_name": {name}})
 
 	// Execute the query against Nagios
 	body, err := client.get(data.Encode(), nagiosURL)
@@ -98,29 +127,62 @@ func (client *Client) GetHost(name string) (*Host, error) {
 		return nil, err
 	}
 
-	err = json.Unmarshal(body, &hostArray)
+	hostArray, err := decodeHosts(body)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if len(hostArray) == 0 {
+		return nil, notFoundError(objectType, name)
+	}
+
 	// We should always return one host object, so we can assign host the value of the first host object in the array
 	host := hostArray[0]
 
-	// We intentionally do not capture the error returned here. FreeVariables is an optional field so if it's not set
-	// This will return an error if it's nil.
-	json.Unmarshal(body, &host.FreeVariables)
-
 	return &host, nil
 }
 
-// UpdateHost updates attributes of an existing host in Nagios
-func (client *Client) UpdateHost(host *Host, currentValue interface{}) error {
-	nagiosURL := client.buildURL(apiType, objectType, http.MethodPut, currentValue.(string))
+// ListHosts retrieves every host from Nagios
+func (client *Client) ListHosts() ([]Host, error) {
+	nagiosURL := client.buildURL(apiType, objectType, http.MethodGet)
 
-	nagiosURL = nagiosURL + setURLParams(host).Encode()
+	body, err := client.get("", nagiosURL)
 
-	_, err := client.put(nagiosURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeHosts(body)
+}
+
+// decodeHosts decodes the list of hosts returned by Nagios
+// Custom variables are returned as attributes starting with an underscore, alongside the regular attributes
+func decodeHosts(body []byte) ([]Host, error) {
+	var rawArray []map[string]interface{}
+
+	err := json.Unmarshal(body, &rawArray)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hostArray := make([]Host, len(rawArray))
+
+	for i, attributes := range rawArray {
+		err = decodeValues(attributes, &hostArray[i])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hostArray, nil
+}
+
+// UpdateHost updates attributes of an existing host in Nagios
+func (client *Client) UpdateHost(host *Host, currentValue interface{}) error {
+	err := client.updateHost(host, currentValue.(string))
 
 	if err != nil {
 		return err
@@ -136,14 +198,102 @@ func (client *Client) UpdateHost(host *Host, currentValue interface{}) error {
 	return nil
 }
 
-// DeleteHost deletes a host from Nagios
-func (client *Client) DeleteHost(name string) ([]byte, error) {
-	nagiosURL := client.buildURL(apiType, objectType, http.MethodDelete, name)
+// updateHost updates attributes of an existing host in Nagios without applying the configuration
+func (client *Client) updateHost(host *Host, name string) error {
+	if err := validateHost(host, false); err != nil {
+		return err
+	}
 
-	data := &url.Values{}
-	data.Set("host_name", name)
+	// Renaming a host also affects the new name, which has to be absent again after a restore
+	if err := client.snapshotBefore(OperationUpdate, hostObjects(name, host.HostName)...); err != nil {
+		return err
+	}
+
+	nagiosURL := client.buildURL(apiType, objectType, http.MethodPut, name)
+
+	params, err := encodeValues(host)
 
-	body, err := client.delete(data, nagiosURL)
+	if err != nil {
+		return err
+	}
+
+	nagiosURL = addQueryParams(nagiosURL, params)
+
+	_, err = client.put(nagiosURL)
+
+	return err
+}
+
+// PatchHost changes only the attributes of an existing host that differ from changes, and removes the
+// attributes named in clearFields, such as "notes_url" or a custom variable like "_LOCATION"
+// Empty fields in changes are left alone. When nothing differs no request is sent, the configuration
+// is not applied and false is returned
+func (client *Client) PatchHost(name string, changes *Host, clearFields ...string) (bool, error) {
+	fieldChanges, err := client.patchHost(name, changes, clearFields...)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(fieldChanges) == 0 {
+		return false, nil
+	}
+
+	err = client.applyConfig()
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// patchHost sends the attributes of an existing host that differ from changes without applying the configuration
+// It returns the changes that were made, which is empty when the host already matched
+func (client *Client) patchHost(name string, changes *Host, clearFields ...string) ([]FieldChange, error) {
+	if err := validateHost(changes, false); err != nil {
+		return nil, err
+	}
+
+	current, err := client.GetHost(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.patchCurrentHost(current, changes, clearFields...)
+}
+
+// patchCurrentHost sends the attributes that differ from a host that was already fetched
+func (client *Client) patchCurrentHost(current *Host, changes *Host, clearFields ...string) ([]FieldChange, error) {
+	fieldChanges, err := DiffHost(current, changes, clearFields...)
+
+	if err != nil || len(fieldChanges) == 0 {
+		return nil, err
+	}
+
+	return fieldChanges, client.putHostChanges(current.HostName, fieldChanges)
+}
+
+// putHostChanges sends a list of attribute changes to an existing host without applying the configuration
+func (client *Client) putHostChanges(name string, fieldChanges []FieldChange) error {
+	params := changeValues(fieldChanges)
+
+	if err := client.snapshotBefore(OperationUpdate, hostObjects(name, params.Get("host_name"))...); err != nil {
+		return err
+	}
+
+	nagiosURL := client.buildURL(apiType, objectType, http.MethodPut, name)
+	nagiosURL = addQueryParams(nagiosURL, params)
+
+	_, err := client.put(nagiosURL)
+
+	return err
+}
+
+// DeleteHost deletes a host from Nagios
+func (client *Client) DeleteHost(name string) ([]byte, error) {
+	body, err := client.deleteHost(name)
 
 	if err != nil {
 		return nil, err
@@ -157,3 +307,17 @@ func (client *Client) DeleteHost(name string) ([]byte, error) {
 
 	return body, nil
 }
+
+// deleteHost deletes a host from Nagios without applying the configuration
+func (client *Client) deleteHost(name string) ([]byte, error) {
+	if err := client.snapshotBefore(OperationDelete, hostObjects(name)...); err != nil {
+		return nil, err
+	}
+
+	nagiosURL := client.buildURL(apiType, objectType, http.MethodDelete, name)
+
+	data := &url.Values{}
+	data.Set("host_name", name)
+
+	return client.delete(data, nagiosURL)
+}
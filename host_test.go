@@ -38,6 +38,15 @@ func createHostObject() *Host {
 	return host
 }
 
+// createNamedHostObject returns a valid host with the given name
+func createNamedHostObject(name string) *Host {
+	host := createHostObject()
+	host.HostName = name
+	host.Alias = name
+
+	return host
+}
+
 func TestHost_newHost(t *testing.T) {
 	if errList := envVarCheck(); errList != nil {
 		t.Fatal(errList)
@@ -0,0 +1,497 @@
+// Package fakenagios provides an in-memory stand-in for the Nagios XI config API, used by the tests
+package fakenagios
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server serves a small subset of the Nagios XI API from memory
+type Server struct {
+	*httptest.Server
+
+	mutex       sync.Mutex
+	objects     map[string][]map[string]string
+	failing     map[string]bool
+	unsupported map[string]bool
+	applies     int
+	writes      int
+
+	// records holds what the objects endpoints return, such as hoststatus, by record type
+	records    map[string][]map[string]string
+	commands   []string
+	downtimeID int
+}
+
+// nameKeys holds the attributes that identify each object type in a PUT or DELETE URL
+var nameKeys = map[string][]string{
+	"host":              {"host_name"},
+	"service":           {"host_name", "service_description"},
+	"hostdependency":    {"config_name"},
+	"servicedependency": {"config_name"},
+	"hostescalation":    {"config_name"},
+	"serviceescalation": {"config_name"},
+}
+
+// requiredKeys are the attributes XI requires when an object is created without force=1
+// Alternatives are separated by |, such as a service's contacts or contact groups
+var requiredKeys = map[string][]string{
+	"host": {"host_name", "address", "max_check_attempts", "check_period", "notification_interval",
+		"notification_period", "contacts|contact_groups"},
+	"service": {"host_name|hostgroup_name", "service_description", "check_command", "max_check_attempts",
+		"check_interval", "retry_interval", "check_period", "notification_interval", "notification_period",
+		"contacts|contact_groups"},
+	"hostgroup":    {"hostgroup_name", "alias"},
+	"servicegroup": {"servicegroup_name", "alias"},
+	"command":      {"command_name", "command_line"},
+	"contact": {"contact_name", "host_notifications_enabled", "service_notifications_enabled",
+		"host_notification_period", "service_notification_period", "host_notification_options",
+		"service_notification_options", "host_notification_commands", "service_notification_commands"},
+	"contactgroup": {"contactgroup_name", "alias"},
+	"timeperiod":   {"timeperiod_name", "alias"},
+}
+
+// listKeys are returned as JSON arrays, the way XI returns them
+var listKeys = map[string]bool{
+	"use":                    true,
+	"contacts":               true,
+	"contact_groups":         true,
+	"parents":                true,
+	"hostgroups":             true,
+	"members":                true,
+	"flap_detection_options": true,
+}
+
+// New starts a fake Nagios XI server
+func New() *Server {
+	server := &Server{
+		objects:     map[string][]map[string]string{},
+		failing:     map[string]bool{},
+		unsupported: map[string]bool{},
+		records:     map[string][]map[string]string{},
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+
+	return server
+}
+
+// Add stores an object without going through the API
+func (server *Server) Add(objectType string, attributes map[string]string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.objects[objectType] = append(server.objects[objectType], copyAttributes(attributes))
+}
+
+// Objects returns a copy of every stored object of a type
+func (server *Server) Objects(objectType string) []map[string]string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var objects []map[string]string
+	for _, object := range server.objects[objectType] {
+		objects = append(objects, copyAttributes(object))
+	}
+
+	return objects
+}
+
+// Find returns the stored object identified by its name attributes, or nil
+func (server *Server) Find(objectType string, names ...string) map[string]string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	index := server.find(objectType, names)
+	if index < 0 {
+		return nil
+	}
+
+	return copyAttributes(server.objects[objectType][index])
+}
+
+// AddRecord stores a record returned by an objects endpoint, such as a hoststatus or servicestatus record
+func (server *Server) AddRecord(recordType string, attributes map[string]string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.records[recordType] = append(server.records[recordType], copyAttributes(attributes))
+}
+
+// Records returns a copy of every stored record of a type, including the scheduleddowntime records
+// created through the API
+func (server *Server) Records(recordType string) []map[string]string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var records []map[string]string
+	for _, record := range server.records[recordType] {
+		records = append(records, copyAttributes(record))
+	}
+
+	return records
+}
+
+// Commands returns every external command sent to system/corecommand, in order
+func (server *Server) Commands() []string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return append([]string(nil), server.commands...)
+}
+
+// Fail makes every write to an object type fail, or succeed again when fail is false
+func (server *Server) Fail(objectType string, fail bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.failing[objectType] = fail
+}
+
+// Unsupported makes every request for an object type answer like an XI version without its endpoint
+func (server *Server) Unsupported(objectType string) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.unsupported[objectType] = true
+}
+
+// Applies returns how many times the configuration was applied
+func (server *Server) Applies() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.applies
+}
+
+// Writes returns how many POST, PUT and DELETE requests changed the configuration
+func (server *Server) Writes() int {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.writes
+}
+
+func (server *Server) handle(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	segments := strings.Split(path, "/")
+
+	if len(segments) < 2 {
+		writeJSON(w, map[string]string{"error": "Unknown API endpoint."})
+		return
+	}
+
+	r.ParseForm()
+
+	if segments[0] == "objects" {
+		server.listRecords(w, segments[1], r.URL.Query())
+		return
+	}
+
+	if segments[0] == "system" {
+		switch segments[1] {
+		case "applyconfig":
+			server.applies++
+			writeJSON(w, map[string]string{"success": "Apply config command has been sent to the backend."})
+		case "status":
+			writeJSON(w, map[string]string{"is_currently_running": "1", "program_start_time": "2019-10-01 10:00:00"})
+		case "corecommand":
+			server.commands = append(server.commands, r.PostForm.Get("cmd"))
+			writeJSON(w, map[string]string{"success": "Core command sent."})
+		case "scheduleddowntime":
+			if r.Method == http.MethodDelete && len(segments) > 2 {
+				server.removeDowntime(w, segments[2])
+			} else {
+				server.scheduleDowntime(w, r.PostForm)
+			}
+		default:
+			writeJSON(w, map[string]string{"error": "Unknown API endpoint."})
+		}
+		return
+	}
+
+	objectType := segments[1]
+	names := segments[2:]
+
+	if server.unsupported[objectType] {
+		writeJSON(w, map[string]string{"error": "Unknown API endpoint."})
+		return
+	}
+
+	if r.Method != http.MethodGet && server.failing[objectType] {
+		writeJSON(w, map[string]string{"error": "Failed to update " + objectType + "."})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		server.list(w, objectType, r.URL.Query())
+	case http.MethodPost:
+		server.writes++
+		server.create(w, objectType, r.PostForm)
+	case http.MethodPut:
+		server.writes++
+		server.update(w, objectType, names, r.URL.Query())
+	case http.MethodDelete:
+		server.writes++
+		server.remove(w, objectType, names)
+	}
+}
+
+func (server *Server) list(w http.ResponseWriter, objectType string, query url.Values) {
+	objects := []map[string]interface{}{}
+
+	for _, object := range server.objects[objectType] {
+		matches := true
+		for key := range query {
+			if key == "apikey" || key == "pretty" {
+				continue
+			}
+			if object[key] != query.Get(key) {
+				matches = false
+			}
+		}
+		if matches {
+			objects = append(objects, encodeObject(object))
+		}
+	}
+
+	writeJSON(w, objects)
+}
+
+// listRecords answers an objects endpoint the way XI 5 does, with the records under their type and a count
+func (server *Server) listRecords(w http.ResponseWriter, endpoint string, query url.Values) {
+	recordType := endpoint
+	if endpoint == "downtime" {
+		recordType = "scheduleddowntime"
+	}
+
+	records := []map[string]string{}
+
+	for _, record := range server.records[recordType] {
+		matches := true
+		for key := range query {
+			if key == "apikey" || key == "pretty" {
+				continue
+			}
+			if record[key] != query.Get(key) {
+				matches = false
+			}
+		}
+		if matches {
+			records = append(records, record)
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{"recordcount": len(records), recordType: records})
+}
+
+// scheduleDowntime stores a scheduleddowntime record for every host and service in the form
+func (server *Server) scheduleDowntime(w http.ResponseWriter, form url.Values) {
+	if form.Get("comment") == "" || form.Get("start") == "" || form.Get("end") == "" {
+		writeJSON(w, map[string]string{"error": "Missing required variables"})
+		return
+	}
+
+	fixed := "1"
+	if form.Get("flexible") == "1" {
+		fixed = "0"
+	}
+
+	add := func(host, service string) {
+		server.downtimeID++
+		server.records["scheduleddowntime"] = append(server.records["scheduleddowntime"], map[string]string{
+			"internal_id":          strconv.Itoa(server.downtimeID),
+			"host_name":            host,
+			"service_description":  service,
+			"comment_data":         form.Get("comment"),
+			"scheduled_start_time": form.Get("start"),
+			"scheduled_end_time":   form.Get("end"),
+			"is_fixed":             fixed,
+		})
+	}
+
+	for _, host := range form["hosts[]"] {
+		add(host, "")
+	}
+
+	var keys []string
+	for key := range form {
+		if strings.HasPrefix(key, "services[") && strings.HasSuffix(key, "][]") {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, service := range form[key] {
+			add(strings.TrimSuffix(strings.TrimPrefix(key, "services["), "][]"), service)
+		}
+	}
+
+	writeJSON(w, map[string]string{"success": "Schedule downtime command(s) sent successfully."})
+}
+
+// removeDowntime deletes a scheduleddowntime record by its internal ID
+func (server *Server) removeDowntime(w http.ResponseWriter, id string) {
+	downtimes := server.records["scheduleddowntime"]
+
+	for i, downtime := range downtimes {
+		if downtime["internal_id"] == id {
+			server.records["scheduleddowntime"] = append(downtimes[:i:i], downtimes[i+1:]...)
+			writeJSON(w, map[string]string{"success": "Remove downtime command(s) sent successfully."})
+			return
+		}
+	}
+
+	writeJSON(w, map[string]string{"error": "Could not find downtime " + id})
+}
+
+func (server *Server) create(w http.ResponseWriter, objectType string, form url.Values) {
+	object := map[string]string{}
+	for key := range form {
+		if key != "apikey" && key != "force" {
+			object[key] = form.Get(key)
+		}
+	}
+
+	if form.Get("force") != "1" {
+		if missing := missingKeys(objectType, object); len(missing) > 0 {
+			writeJSON(w, map[string]interface{}{"error": "Missing required variables", "missing": missing})
+			return
+		}
+	}
+
+	var names []string
+	for _, key := range keysFor(objectType) {
+		names = append(names, object[key])
+	}
+
+	if index := server.find(objectType, names); index >= 0 {
+		server.objects[objectType][index] = object
+	} else {
+		server.objects[objectType] = append(server.objects[objectType], object)
+	}
+
+	writeJSON(w, map[string]string{"success": "Successfully added " + strings.Join(names, " ") + " to the system."})
+}
+
+func (server *Server) update(w http.ResponseWriter, objectType string, names []string, query url.Values) {
+	index := server.find(objectType, names)
+	if index < 0 {
+		writeJSON(w, map[string]string{"error": "Could not find a unique id for this object"})
+		return
+	}
+
+	object := server.objects[objectType][index]
+	for key := range query {
+		if key == "apikey" || key == "pretty" {
+			continue
+		}
+		if query.Get(key) == "" {
+			delete(object, key)
+		} else {
+			object[key] = query.Get(key)
+		}
+	}
+
+	writeJSON(w, map[string]string{"success": "Updated " + strings.Join(names, " ") + " in the system."})
+}
+
+func (server *Server) remove(w http.ResponseWriter, objectType string, names []string) {
+	index := server.find(objectType, names)
+	if index < 0 {
+		writeJSON(w, map[string]string{"error": "Could not find a unique id for this object"})
+		return
+	}
+
+	objects := server.objects[objectType]
+	server.objects[objectType] = append(objects[:index:index], objects[index+1:]...)
+
+	writeJSON(w, map[string]string{"success": "Removed " + strings.Join(names, " ") + " from the system."})
+}
+
+func (server *Server) find(objectType string, names []string) int {
+	keys := keysFor(objectType)
+	if len(names) != len(keys) {
+		return -1
+	}
+
+	for i, object := range server.objects[objectType] {
+		matches := true
+		for k, key := range keys {
+			if object[key] != names[k] {
+				matches = false
+			}
+		}
+		if matches {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func encodeObject(object map[string]string) map[string]interface{} {
+	encoded := map[string]interface{}{}
+	for key, value := range object {
+		if listKeys[key] {
+			encoded[key] = strings.Split(value, ",")
+		} else {
+			encoded[key] = value
+		}
+	}
+
+	return encoded
+}
+
+// missingKeys returns the required attributes of an object type that are not set, see requiredKeys
+func missingKeys(objectType string, object map[string]string) []string {
+	var missing []string
+
+	for _, required := range requiredKeys[objectType] {
+		set := false
+		for _, key := range strings.Split(required, "|") {
+			if object[key] != "" {
+				set = true
+			}
+		}
+		if !set {
+			missing = append(missing, strings.Split(required, "|")[0])
+		}
+	}
+
+	return missing
+}
+
+func keysFor(objectType string) []string {
+	if keys, ok := nameKeys[objectType]; ok {
+		return keys
+	}
+
+	return []string{objectType + "_name"}
+}
+
+func copyAttributes(attributes map[string]string) map[string]string {
+	copied := map[string]string{}
+	for key, value := range attributes {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,58 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// Apply makes the changes in the plan in order and applies the configuration once at the end
+// It stops at the first write that fails without applying the configuration, so Nagios keeps running
+// the configuration it had. Planning again shows what is left to do
+func (plan *Plan) Apply(client *gonagios.Client) error {
+	if plan.Empty() {
+		return nil
+	}
+
+	session := client.NewConfigSession()
+
+	for _, change := range plan.Changes {
+		object := change.Object
+
+		var err error
+
+		switch change.Action {
+		case ActionCreate:
+			err = session.NewObject(&object)
+		case ActionUpdate:
+			err = session.UpdateObject(changedAttributes(change))
+		case ActionDelete:
+			err = session.DeleteObject(&object)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s %s '%s': %w", change.Action, object.Type, object.Name(), err)
+		}
+	}
+
+	return session.Commit()
+}
+
+// changedAttributes returns the object of an update with only the attributes that change,
+// and the attributes that identify it
+func changedAttributes(change Change) *gonagios.ConfigObject {
+	object := &gonagios.ConfigObject{
+		Type:       change.Object.Type,
+		Attributes: map[string]string{},
+	}
+
+	for _, key := range object.NameKeys() {
+		object.Attributes[key] = change.Object.Attributes[key]
+	}
+
+	for _, field := range change.Fields {
+		object.Attributes[field.Field] = field.New
+	}
+
+	return object
+}
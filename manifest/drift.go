@@ -0,0 +1,106 @@
+package manifest
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// Drift is the difference between the objects in a manifest and the live configuration
+type Drift struct {
+	// Missing objects are in the manifest but not in XI
+	Missing []ObjectDrift `json:"missing"`
+	// Unmanaged objects are in XI but not in the manifest. Only the object types in the manifest are checked
+	Unmanaged []ObjectDrift `json:"unmanaged"`
+	// Changed objects have attributes that differ from the manifest
+	Changed []ObjectDrift `json:"changed"`
+}
+
+// ObjectDrift is a single object that drifted, with the attributes that differ
+type ObjectDrift struct {
+	Type   gonagios.ObjectType    `json:"type"`
+	Name   string                 `json:"name"`
+	Fields []gonagios.FieldChange `json:"fields,omitempty"`
+}
+
+// Drift compares the manifest with the live configuration without changing anything
+// Objects can come from manifest files or be built in code, see gonagios.Host.ConfigObject
+func (manifest *Manifest) Drift(client *gonagios.Client) (*Drift, error) {
+	// Every object missing from the manifest is reported, whoever owns it
+	plan, err := manifest.plan(client, true, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	drift := &Drift{
+		Missing:   []ObjectDrift{},
+		Unmanaged: []ObjectDrift{},
+		Changed:   []ObjectDrift{},
+	}
+
+	for _, change := range plan.Changes {
+		object := ObjectDrift{Type: change.Object.Type, Name: change.Object.Name()}
+
+		switch change.Action {
+		case ActionCreate:
+			drift.Missing = append(drift.Missing, object)
+		case ActionUpdate:
+			object.Fields = change.Fields
+			drift.Changed = append(drift.Changed, object)
+		case ActionDelete:
+			drift.Unmanaged = append(drift.Unmanaged, object)
+		}
+	}
+
+	return drift, nil
+}
+
+// Drifted returns true when anything differs
+func (drift *Drift) Drifted() bool {
+	return len(drift.Missing) > 0 || len(drift.Unmanaged) > 0 || len(drift.Changed) > 0
+}
+
+// WriteText writes the report for people to read
+func (drift *Drift) WriteText(w io.Writer) error {
+	var text strings.Builder
+
+	if !drift.Drifted() {
+		text.WriteString("No drift\n")
+	}
+
+	for _, object := range drift.Missing {
+		text.WriteString("missing   " + string(object.Type) + " " + object.Name + "\n")
+	}
+
+	for _, object := range drift.Unmanaged {
+		text.WriteString("unmanaged " + string(object.Type) + " " + object.Name + "\n")
+	}
+
+	for _, object := range drift.Changed {
+		text.WriteString("changed   " + string(object.Type) + " " + object.Name + "\n")
+
+		for _, field := range object.Fields {
+			text.WriteString("    " + field.Field + ": " + strconv.Quote(field.Old) + " in XI, " + strconv.Quote(field.New) + " wanted\n")
+		}
+	}
+
+	if drift.Drifted() {
+		text.WriteString("Drift: " + strconv.Itoa(len(drift.Missing)) + " missing, " + strconv.Itoa(len(drift.Unmanaged)) + " unmanaged, " + strconv.Itoa(len(drift.Changed)) + " changed\n")
+	}
+
+	_, err := io.WriteString(w, text.String())
+
+	return err
+}
+
+// WriteJSON writes the report as JSON for other tools
+func (drift *Drift) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(drift)
+}
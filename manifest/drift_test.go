@@ -0,0 +1,80 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrift_report(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "web01", "address": "10.0.0.9"})
+	server.Add("host", map[string]string{"host_name": "handmade", "address": "10.0.0.8"})
+
+	client := gonagios.NewClient(server.URL, "token123")
+
+	web01, err := (&gonagios.Host{HostName: "web01", Address: "10.0.0.1"}).ConfigObject()
+	assert.NoError(t, err)
+
+	web02, err := (&gonagios.Host{HostName: "web02", Address: "10.0.0.2"}).ConfigObject()
+	assert.NoError(t, err)
+
+	manifest := &Manifest{Objects: []gonagios.ConfigObject{*web01, *web02}}
+
+	drift, err := manifest.Drift(client)
+
+	assert.NoError(t, err)
+	assert.True(t, drift.Drifted())
+	assert.Equal(t, []ObjectDrift{{Type: gonagios.ObjectHost, Name: "web02"}}, drift.Missing)
+	assert.Equal(t, []ObjectDrift{{Type: gonagios.ObjectHost, Name: "handmade"}}, drift.Unmanaged)
+	assert.Equal(t, []ObjectDrift{{
+		Type:   gonagios.ObjectHost,
+		Name:   "web01",
+		Fields: []gonagios.FieldChange{{Field: "address", Old: "10.0.0.9", New: "10.0.0.1"}},
+	}}, drift.Changed)
+
+	var text bytes.Buffer
+	assert.NoError(t, drift.WriteText(&text))
+	assert.Equal(t, "missing   host web02\nunmanaged host handmade\nchanged   host web01\n"+
+		"    address: \"10.0.0.9\" in XI, \"10.0.0.1\" wanted\nDrift: 1 missing, 1 unmanaged, 1 changed\n", text.String())
+
+	var output bytes.Buffer
+	assert.NoError(t, drift.WriteJSON(&output))
+
+	var decoded Drift
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, *drift, decoded)
+
+	// Drift never changes anything
+	assert.Equal(t, 0, server.Writes())
+}
+
+func TestDrift_none(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "web01", "address": "10.0.0.1"})
+
+	client := gonagios.NewClient(server.URL, "token123")
+
+	manifest, err := Parse([]byte("hosts:\n  - host_name: web01\n    address: 10.0.0.1\n"))
+	assert.NoError(t, err)
+
+	drift, err := manifest.Drift(client)
+	assert.NoError(t, err)
+	assert.False(t, drift.Drifted())
+
+	var text bytes.Buffer
+	assert.NoError(t, drift.WriteText(&text))
+	assert.Equal(t, "No drift\n", text.String())
+
+	var output bytes.Buffer
+	assert.NoError(t, drift.WriteJSON(&output))
+	assert.JSONEq(t, `{"missing":[],"unmanaged":[],"changed":[]}`, output.String())
+}
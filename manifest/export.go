@@ -0,0 +1,188 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is the file format objects are exported in
+type Format string
+
+// Export formats. YAML and JSON files can be loaded back as a manifest
+const (
+	FormatYAML   Format = "yaml"
+	FormatJSON   Format = "json"
+	FormatNagios Format = "cfg"
+)
+
+// unsafeFileChars matches everything we do not want in an exported file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Export writes every object of the given types, or of every manifest type when none are given, to dir
+// Each object is written to its own file, dir/<section>/<name>.<format>, such as hosts/web01.yaml or
+// services/web01__PING.yaml, with its attributes in name order. Files left in those directories by an
+// earlier export are removed first, so exporting again shows deleted objects in a diff.
+// Templates and other objects without a name, such as a dependency without a config_name, are skipped in every
+// format since a manifest cannot load them back. When no types are given, the types the XI version has no
+// endpoint for are skipped too. The paths of the written files are returned in order
+func Export(client *gonagios.Client, dir string, format Format, types ...gonagios.ObjectType) ([]string, error) {
+	if format != FormatYAML && format != FormatJSON && format != FormatNagios {
+		return nil, errors.New("unknown export format '" + string(format) + "'")
+	}
+
+	every := len(types) == 0
+
+	if every {
+		types = ObjectTypes
+	}
+
+	var files []string
+
+	for _, objectType := range types {
+		section, ok := sections[objectType]
+
+		if !ok {
+			return nil, errors.New("cannot export " + string(objectType) + " objects")
+		}
+
+		objects, err := client.ListObjects(objectType)
+
+		if every && errors.Is(err, gonagios.ErrUnsupported) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		sortObjects(objects)
+
+		typeDir := filepath.Join(dir, section)
+
+		if err := clearExport(typeDir, format); err != nil {
+			return nil, err
+		}
+
+		used := map[string]bool{}
+
+		for i := range objects {
+			object := &objects[i]
+
+			if object.Names() == nil {
+				continue
+			}
+
+			data, err := encodeObject(object, format)
+
+			if err != nil {
+				return nil, err
+			}
+
+			path := filepath.Join(typeDir, exportFileName(object, used)+"."+string(format))
+
+			if err := ioutil.WriteFile(path, data, 0644); err != nil {
+				return nil, err
+			}
+
+			files = append(files, path)
+		}
+	}
+
+	return files, nil
+}
+
+// encodeObject writes a single object in an export format
+func encodeObject(object *gonagios.ConfigObject, format Format) ([]byte, error) {
+	document := map[string][]map[string]string{
+		sections[object.Type]: {object.Attributes},
+	}
+
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(document)
+	case FormatJSON:
+		data, err := json.MarshalIndent(document, "", "  ")
+		return append(data, '\n'), err
+	}
+
+	return []byte(defineObject(object)), nil
+}
+
+// defineObject writes an object in native Nagios define syntax, with the values lined up
+func defineObject(object *gonagios.ConfigObject) string {
+	keys := sortedKeys(object.Attributes)
+
+	width := 0
+	for _, key := range keys {
+		if len(key) > width {
+			width = len(key)
+		}
+	}
+
+	var text strings.Builder
+
+	text.WriteString("define " + string(object.Type) + " {\n")
+
+	for _, key := range keys {
+		if object.Attributes[key] == "" {
+			continue
+		}
+		text.WriteString("    " + key + strings.Repeat(" ", width-len(key)+4) + object.Attributes[key] + "\n")
+	}
+
+	text.WriteString("}\n")
+
+	return text.String()
+}
+
+// exportFileName returns a file name for an object that no other object of its type has used
+func exportFileName(object *gonagios.ConfigObject, used map[string]bool) string {
+	base := unsafeFileChars.ReplaceAllString(strings.Join(object.Names(), "__"), "_")
+	name := base
+
+	for i := 2; used[name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+
+	used[name] = true
+
+	return name
+}
+
+// clearExport creates the directory of an object type and removes the files of an earlier export in it
+func clearExport(typeDir string, format Format) error {
+	if err := os.MkdirAll(typeDir, 0755); err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(typeDir, "*."+string(format)))
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortObjects orders objects by their names, so every export writes them in the same order
+func sortObjects(objects []gonagios.ConfigObject) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return strings.Join(objects[i].Names(), "\x00") < strings.Join(objects[j].Names(), "\x00")
+	})
+}
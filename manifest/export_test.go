@@ -0,0 +1,152 @@
+package manifest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+// newExportServer returns a fake XI with a few objects of different types
+func newExportServer() *fakenagios.Server {
+	server := fakenagios.New()
+
+	server.Add("host", map[string]string{"host_name": "web02", "address": "10.0.0.2", "use": "generic-host"})
+	server.Add("host", map[string]string{"host_name": "web01", "address": "10.0.0.1", "contacts": "nagiosadmin,oncall"})
+	server.Add("host", map[string]string{"name": "generic-host", "register": "0"})
+	server.Add("service", map[string]string{"host_name": "web01", "service_description": "HTTP/S", "check_command": "check_http"})
+	server.Add("command", map[string]string{"command_name": "check_http", "command_line": "$USER1$/check_http -H $HOSTADDRESS$"})
+	server.Add("hostdependency", map[string]string{"config_name": "web02-on-web01", "host_name": "web01", "dependent_host_name": "web02"})
+	server.Add("hostdependency", map[string]string{"host_name": "web01", "dependent_host_name": "web03"})
+	server.Add("serviceescalation", map[string]string{"config_name": "http-oncall", "host_name": "web01", "service_description": "HTTP/S", "contacts": "oncall"})
+
+	return server
+}
+
+func TestExport_yaml(t *testing.T) {
+	server := newExportServer()
+	defer server.Close()
+
+	client := gonagios.NewClient(server.URL, "token123")
+	dir := t.TempDir()
+
+	files, err := Export(client, dir, FormatYAML)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "commands", "check_http.yaml"),
+		filepath.Join(dir, "hosts", "web01.yaml"),
+		filepath.Join(dir, "hosts", "web02.yaml"),
+		filepath.Join(dir, "services", "web01__HTTP_S.yaml"),
+		filepath.Join(dir, "hostdependencies", "web02-on-web01.yaml"),
+		filepath.Join(dir, "serviceescalations", "http-oncall.yaml"),
+	}, files)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "hosts", "web01.yaml"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hosts:\n- address: 10.0.0.1\n  contacts: nagiosadmin,oncall\n  host_name: web01\n", string(data))
+
+	// An exported directory loads back as a manifest that matches XI
+	manifest, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Objects, 6)
+
+	plan, err := manifest.Plan(client, PlanOptions{Prune: true})
+	assert.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+}
+
+func TestExport_skipsTemplates(t *testing.T) {
+	server := newExportServer()
+	defer server.Close()
+
+	client := gonagios.NewClient(server.URL, "token123")
+	dir := t.TempDir()
+
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatNagios} {
+		files, err := Export(client, dir, format, gonagios.ObjectHost)
+
+		assert.NoError(t, err)
+		assert.Len(t, files, 2, string(format))
+
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+
+			assert.NoError(t, err)
+			assert.NotContains(t, string(data), "register", file)
+		}
+	}
+}
+
+func TestExport_unsupportedTypes(t *testing.T) {
+	server := newExportServer()
+	defer server.Close()
+
+	server.Unsupported("serviceescalation")
+
+	client := gonagios.NewClient(server.URL, "token123")
+	dir := t.TempDir()
+
+	// Exporting every type skips the ones this XI version has no endpoint for
+	files, err := Export(client, dir, FormatYAML)
+
+	assert.NoError(t, err)
+	assert.Len(t, files, 5)
+
+	_, err = Export(client, dir, FormatYAML, gonagios.ObjectServiceEscalation)
+
+	assert.True(t, errors.Is(err, gonagios.ErrUnsupported))
+}
+
+func TestExport_removesStaleFiles(t *testing.T) {
+	server := newExportServer()
+	defer server.Close()
+
+	client := gonagios.NewClient(server.URL, "token123")
+	dir := t.TempDir()
+
+	_, err := Export(client, dir, FormatJSON, gonagios.ObjectHost)
+	assert.NoError(t, err)
+
+	_, err = client.DeleteHost("web02")
+	assert.NoError(t, err)
+
+	files, err := Export(client, dir, FormatJSON, gonagios.ObjectHost)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "hosts", "web01.json")}, files)
+
+	_, err = os.Stat(filepath.Join(dir, "hosts", "web02.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	data, err := ioutil.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "\"hosts\": [\n")
+}
+
+func TestExport_nagios(t *testing.T) {
+	server := newExportServer()
+	defer server.Close()
+
+	client := gonagios.NewClient(server.URL, "token123")
+	dir := t.TempDir()
+
+	_, err := Export(client, dir, FormatNagios, gonagios.ObjectCommand)
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "commands", "check_http.cfg"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "define command {\n    command_line    $USER1$/check_http -H $HOSTADDRESS$\n    command_name    check_http\n}\n", string(data))
+}
+
+func TestExport_unknownFormat(t *testing.T) {
+	_, err := Export(gonagios.NewClient("http://localhost", "token123"), t.TempDir(), Format("xml"))
+
+	assert.Error(t, err)
+}
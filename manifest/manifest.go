@@ -0,0 +1,274 @@
+// Package manifest describes Nagios objects in YAML files, and plans and applies the changes that make
+// Nagios XI match them
+//
+// A manifest lists objects by type. Lists are written as YAML sequences, booleans are sent as 1 or 0 and
+// custom variables are attributes starting with an underscore:
+//
+//	commands:
+//	  - command_name: check_ping
+//	    command_line: $USER1$/check_ping -H $HOSTADDRESS$ -w $ARG1$ -c $ARG2$
+//	hosts:
+//	  - host_name: web01
+//	    address: 10.0.0.1
+//	    use: [generic-host]
+//	    contacts: [nagiosadmin]
+//	    active_checks_enabled: true
+//	    _location: dc1
+package manifest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+	"gopkg.in/yaml.v2"
+)
+
+// ObjectTypes are the object types a manifest can hold, in the order they are created
+// Objects are deleted in the reverse order, so nothing is removed while another object still refers to it
+var ObjectTypes = []gonagios.ObjectType{
+	gonagios.ObjectTimePeriod,
+	gonagios.ObjectCommand,
+	gonagios.ObjectContact,
+	gonagios.ObjectContactGroup,
+	gonagios.ObjectHostGroup,
+	gonagios.ObjectHost,
+	gonagios.ObjectServiceGroup,
+	gonagios.ObjectService,
+	gonagios.ObjectHostDependency,
+	gonagios.ObjectServiceDependency,
+	gonagios.ObjectHostEscalation,
+	gonagios.ObjectServiceEscalation,
+}
+
+// sections are the keys of each object type in a manifest file
+var sections = map[gonagios.ObjectType]string{
+	gonagios.ObjectTimePeriod:        "timeperiods",
+	gonagios.ObjectCommand:           "commands",
+	gonagios.ObjectContact:           "contacts",
+	gonagios.ObjectContactGroup:      "contactgroups",
+	gonagios.ObjectHostGroup:         "hostgroups",
+	gonagios.ObjectHost:              "hosts",
+	gonagios.ObjectServiceGroup:      "servicegroups",
+	gonagios.ObjectService:           "services",
+	gonagios.ObjectHostDependency:    "hostdependencies",
+	gonagios.ObjectServiceDependency: "servicedependencies",
+	gonagios.ObjectHostEscalation:    "hostescalations",
+	gonagios.ObjectServiceEscalation: "serviceescalations",
+}
+
+// Manifest is the set of objects described by one or more YAML files
+type Manifest struct {
+	Objects []gonagios.ConfigObject
+	// sources records the file each object was loaded from, for error messages
+	sources map[string]string
+}
+
+// Load reads manifest files. A directory loads every .yaml, .yml and .json file in it and in the directories
+// below it, in path order. Objects from every file are merged, and an object defined twice is an error
+func Load(paths ...string) (*Manifest, error) {
+	manifest := &Manifest{sources: map[string]string{}}
+
+	for _, path := range paths {
+		files, err := manifestFiles(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if err := manifest.add(data, file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+// Parse reads a manifest from YAML
+func Parse(data []byte) (*Manifest, error) {
+	manifest := &Manifest{sources: map[string]string{}}
+
+	if err := manifest.add(data, "manifest"); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Types returns the object types that have at least one object in the manifest, in creation order
+func (manifest *Manifest) Types() []gonagios.ObjectType {
+	var types []gonagios.ObjectType
+
+	for _, objectType := range ObjectTypes {
+		for _, object := range manifest.Objects {
+			if object.Type == objectType {
+				types = append(types, objectType)
+				break
+			}
+		}
+	}
+
+	return types
+}
+
+// manifestFiles returns the manifest files at a path
+func manifestFiles(path string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if file == path && !info.IsDir() {
+			files = append(files, file)
+			return nil
+		}
+
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				files = append(files, file)
+			}
+		}
+
+		return nil
+	})
+
+	sort.Strings(files)
+
+	return files, err
+}
+
+// add parses a manifest file and adds its objects
+func (manifest *Manifest) add(data []byte, source string) error {
+	var doc map[string][]map[string]interface{}
+
+	// A section or attribute written twice is an error, instead of the last one silently winning
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return errors.New(source + ": " + err.Error())
+	}
+
+	known := map[string]bool{}
+	for _, section := range sections {
+		known[section] = true
+	}
+
+	for _, section := range sortedSections(doc) {
+		if !known[section] {
+			return errors.New(source + ": unknown section '" + section + "'")
+		}
+	}
+
+	for _, objectType := range ObjectTypes {
+		for i, raw := range doc[sections[objectType]] {
+			object, err := newObject(objectType, raw)
+
+			if err != nil {
+				return errors.New(source + ": " + string(objectType) + " " + strconv.Itoa(i+1) + ": " + err.Error())
+			}
+
+			key := objectKey(object)
+
+			if other, ok := manifest.sources[key]; ok {
+				return errors.New(source + ": " + string(objectType) + " '" + object.Name() + "' is already defined in " + other)
+			}
+
+			manifest.sources[key] = source
+			manifest.Objects = append(manifest.Objects, *object)
+		}
+	}
+
+	return nil
+}
+
+// newObject converts the attributes of an object in a manifest to the strings Nagios expects
+func newObject(objectType gonagios.ObjectType, raw map[string]interface{}) (*gonagios.ConfigObject, error) {
+	object := &gonagios.ConfigObject{
+		Type:       objectType,
+		Attributes: map[string]string{},
+	}
+
+	for name, value := range raw {
+		text, err := attributeValue(value)
+
+		if err != nil {
+			return nil, errors.New(name + ": " + err.Error())
+		}
+
+		// Nagios stores custom variable names in upper case
+		if strings.HasPrefix(name, "_") {
+			name = strings.ToUpper(name)
+		}
+
+		object.Attributes[name] = text
+	}
+
+	if object.Names() == nil {
+		return nil, errors.New("missing " + strings.Join(object.NameKeys(), " and "))
+	}
+
+	return object, nil
+}
+
+// attributeValue converts a YAML value to a Nagios attribute
+func attributeValue(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	case bool:
+		if typed {
+			return "1", nil
+		}
+		return "0", nil
+	case int:
+		return strconv.Itoa(typed), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			text, err := attributeValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	return "", errors.New("must be a string, number, boolean or list")
+}
+
+// sortedSections returns the sections of a manifest file in order
+func sortedSections(doc map[string][]map[string]interface{}) []string {
+	names := make([]string, 0, len(doc))
+
+	for name := range doc {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// objectKey identifies an object across every type
+func objectKey(object *gonagios.ConfigObject) string {
+	return string(object.Type) + "\x00" + strings.Join(object.Names(), "\x00")
+}
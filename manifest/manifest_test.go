@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/stretchr/testify/assert"
+)
+
+const testManifest = `
+hosts:
+  - host_name: web01
+    address: 10.0.0.1
+    use: [generic-host]
+    contacts: [nagiosadmin, oncall]
+    max_check_attempts: 5
+    active_checks_enabled: true
+    low_flap_threshold: 12.5
+    notes_url: null
+    _location: dc1
+commands:
+  - command_name: check_ping
+    command_line: $USER1$/check_ping -H $HOSTADDRESS$
+services:
+  - host_name: web01
+    service_description: PING
+    use: [generic-service]
+    check_command: check_ping
+`
+
+func TestManifest_parse(t *testing.T) {
+	manifest, err := Parse([]byte(testManifest))
+
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Objects, 3)
+
+	// Objects are held in creation order, whatever order the file lists them in
+	assert.Equal(t, gonagios.ObjectCommand, manifest.Objects[0].Type)
+	assert.Equal(t, gonagios.ObjectHost, manifest.Objects[1].Type)
+	assert.Equal(t, gonagios.ObjectService, manifest.Objects[2].Type)
+
+	assert.Equal(t, map[string]string{
+		"host_name":             "web01",
+		"address":               "10.0.0.1",
+		"use":                   "generic-host",
+		"contacts":              "nagiosadmin,oncall",
+		"max_check_attempts":    "5",
+		"active_checks_enabled": "1",
+		"low_flap_threshold":    "12.5",
+		"notes_url":             "",
+		"_LOCATION":             "dc1",
+	}, manifest.Objects[1].Attributes)
+
+	assert.Equal(t, []gonagios.ObjectType{gonagios.ObjectCommand, gonagios.ObjectHost, gonagios.ObjectService}, manifest.Types())
+}
+
+func TestManifest_parseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		problem  string
+	}{
+		{"unknown section", "widgets:\n  - widget_name: a\n", "widgets"},
+		{"missing name", "hosts:\n  - address: 10.0.0.1\n", "missing host_name"},
+		{"missing description", "services:\n  - host_name: web01\n", "missing host_name and service_description"},
+		{"nested map", "hosts:\n  - host_name: web01\n    address: {ip: 10.0.0.1}\n", "address"},
+		{"duplicate", "hosts:\n  - host_name: web01\n  - host_name: web01\n", "already defined"},
+		{"duplicate section", "hosts:\n  - host_name: web01\nhosts:\n  - host_name: web02\n", "already set"},
+		{"duplicate attribute", "hosts:\n  - host_name: web01\n    address: 10.0.0.1\n    address: 10.0.0.2\n", "already set"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse([]byte(test.manifest))
+
+		if assert.Error(t, err, test.name) {
+			assert.Contains(t, err.Error(), test.problem, test.name)
+		}
+	}
+}
+
+func TestManifest_load(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "hosts.yaml"), []byte("hosts:\n  - host_name: web01\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "commands.yml"), []byte("commands:\n  - command_name: check_ping\n"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600))
+
+	manifest, err := Load(dir)
+
+	assert.NoError(t, err)
+	assert.Len(t, manifest.Objects, 2)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "more.yaml"), []byte("hosts:\n  - host_name: web01\n"), 0600))
+
+	_, err = Load(dir)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "hosts.yaml")
+}
@@ -0,0 +1,233 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// Action is what a plan does to an object
+type Action string
+
+// Actions of a Change
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is a single object the plan creates, updates or deletes
+// Object holds the attributes from the manifest, or the live attributes of an object that is deleted.
+// Fields lists every attribute that changes, and is empty for a delete
+type Change struct {
+	Action Action
+	Object gonagios.ConfigObject
+	Fields []gonagios.FieldChange
+}
+
+// Plan is the list of changes that make Nagios match a manifest, in the order they are applied
+type Plan struct {
+	Changes []Change
+}
+
+// PlanOptions controls how a plan is computed
+type PlanOptions struct {
+	// Prune deletes live objects that are not in the manifest. Only the object types that appear in the
+	// manifest are pruned, and templates (register 0) are never deleted. When the client has an Owner,
+	// only the objects it owns are deleted
+	Prune bool
+	// MaxDeletions makes Plan fail with gonagios.ErrTooManyDeletions when it would delete more objects.
+	// Zero means there is no limit
+	MaxDeletions int
+}
+
+// Plan compares the manifest with the live configuration and returns the changes needed to make them match
+// Only the attributes in the manifest are compared, so attributes set outside of it are left alone.
+// An attribute set to an empty value or null in the manifest is cleared
+func (manifest *Manifest) Plan(client *gonagios.Client, options PlanOptions) (*Plan, error) {
+	plan, err := manifest.plan(client, options.Prune, client.Owner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, deletes := plan.Count(); options.MaxDeletions > 0 && deletes > options.MaxDeletions {
+		return nil, fmt.Errorf("the plan would delete %d objects, more than the limit of %d: %w", deletes, options.MaxDeletions, gonagios.ErrTooManyDeletions)
+	}
+
+	return plan, nil
+}
+
+// plan computes the changes that make Nagios match the manifest
+// When prune is set, live objects missing from the manifest are deleted if owner is nil or owns them
+func (manifest *Manifest) plan(client *gonagios.Client, prune bool, owner *gonagios.Ownership) (*Plan, error) {
+	plan := &Plan{}
+
+	// deletes holds the deletes of each object type, in creation order
+	var deletes [][]Change
+
+	for _, objectType := range manifest.Types() {
+		live, err := client.ListObjects(objectType)
+
+		if err != nil {
+			return nil, err
+		}
+
+		liveObjects := map[string]*gonagios.ConfigObject{}
+		for i := range live {
+			if live[i].Names() != nil {
+				liveObjects[objectKey(&live[i])] = &live[i]
+			}
+		}
+
+		desired := map[string]bool{}
+
+		for _, object := range manifest.Objects {
+			if object.Type != objectType {
+				continue
+			}
+
+			key := objectKey(&object)
+			desired[key] = true
+
+			current, ok := liveObjects[key]
+
+			if !ok {
+				plan.Changes = append(plan.Changes, Change{
+					Action: ActionCreate,
+					Object: object,
+					Fields: diffAttributes(nil, object.Attributes),
+				})
+				continue
+			}
+
+			if fields := diffAttributes(current.Attributes, object.Attributes); len(fields) > 0 {
+				plan.Changes = append(plan.Changes, Change{
+					Action: ActionUpdate,
+					Object: object,
+					Fields: fields,
+				})
+			}
+		}
+
+		if !prune {
+			continue
+		}
+
+		var typeDeletes []Change
+
+		for i := range live {
+			key := objectKey(&live[i])
+
+			if live[i].Names() == nil || desired[key] || live[i].Attributes["register"] == "0" {
+				continue
+			}
+
+			if owner != nil && !owner.Owns(&live[i]) {
+				continue
+			}
+
+			typeDeletes = append(typeDeletes, Change{Action: ActionDelete, Object: live[i]})
+		}
+
+		deletes = append(deletes, typeDeletes)
+	}
+
+	// Delete the object types in the reverse order of creation, so objects are removed before the objects they refer to
+	for i := len(deletes) - 1; i >= 0; i-- {
+		plan.Changes = append(plan.Changes, deletes[i]...)
+	}
+
+	return plan, nil
+}
+
+// Empty returns true when Nagios already matches the manifest
+func (plan *Plan) Empty() bool {
+	return len(plan.Changes) == 0
+}
+
+// Count returns how many objects the plan creates, updates and deletes
+func (plan *Plan) Count() (creates, updates, deletes int) {
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case ActionCreate:
+			creates++
+		case ActionUpdate:
+			updates++
+		case ActionDelete:
+			deletes++
+		}
+	}
+
+	return creates, updates, deletes
+}
+
+// String describes every change in the plan, one object per line followed by its changed attributes
+func (plan *Plan) String() string {
+	var text strings.Builder
+
+	symbols := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+
+	for _, change := range plan.Changes {
+		text.WriteString(symbols[change.Action] + " " + string(change.Action) + " " + string(change.Object.Type) + " " + change.Object.Name() + "\n")
+
+		for _, field := range change.Fields {
+			switch {
+			case change.Action == ActionCreate:
+				text.WriteString("    " + field.Field + ": " + strconv.Quote(field.New) + "\n")
+			case field.New == "":
+				text.WriteString("    " + field.Field + ": " + strconv.Quote(field.Old) + " -> (cleared)\n")
+			default:
+				text.WriteString("    " + field.Field + ": " + strconv.Quote(field.Old) + " -> " + strconv.Quote(field.New) + "\n")
+			}
+		}
+	}
+
+	creates, updates, deletes := plan.Count()
+
+	text.WriteString("Plan: " + strconv.Itoa(creates) + " to create, " + strconv.Itoa(updates) + " to update, " + strconv.Itoa(deletes) + " to delete\n")
+
+	return text.String()
+}
+
+// diffAttributes returns the attributes of desired that differ from current, in name order
+func diffAttributes(current, desired map[string]string) []gonagios.FieldChange {
+	var fields []gonagios.FieldChange
+
+	for _, name := range sortedKeys(desired) {
+		if normalizeValue(current[name]) == normalizeValue(desired[name]) {
+			continue
+		}
+
+		fields = append(fields, gonagios.FieldChange{Field: name, Old: current[name], New: desired[name]})
+	}
+
+	return fields
+}
+
+// normalizeValue ignores the whitespace around commas, which Nagios does not keep
+func normalizeValue(value string) string {
+	items := strings.Split(value, ",")
+
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+
+	return strings.Join(items, ",")
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(attributes map[string]string) []string {
+	keys := make([]string, 0, len(attributes))
+
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan_planAndApply(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "web01", "address": "10.0.0.9", "notes_url": "http://wiki/web01", "alias": "Web"})
+	server.Add("host", map[string]string{"host_name": "old01", "address": "10.0.0.8"})
+	server.Add("host", map[string]string{"name": "generic-host", "register": "0"})
+	server.Add("timeperiod", map[string]string{"timeperiod_name": "24x7"})
+
+	client := gonagios.NewClient(server.URL, "token123")
+
+	manifest, err := Parse([]byte(testManifest))
+	assert.NoError(t, err)
+
+	plan, err := manifest.Plan(client, PlanOptions{Prune: true})
+	assert.NoError(t, err)
+
+	var actions []string
+	for _, change := range plan.Changes {
+		actions = append(actions, string(change.Action)+" "+string(change.Object.Type)+" "+change.Object.Name())
+	}
+
+	assert.Equal(t, []string{
+		"create command check_ping",
+		"update host web01",
+		"create service web01 PING",
+		"delete host old01",
+	}, actions)
+
+	assert.Contains(t, plan.Changes[1].Fields, gonagios.FieldChange{Field: "address", Old: "10.0.0.9", New: "10.0.0.1"})
+	assert.Contains(t, plan.String(), `notes_url: "http://wiki/web01" -> (cleared)`)
+	assert.Contains(t, plan.String(), "Plan: 2 to create, 1 to update, 1 to delete")
+
+	assert.NoError(t, plan.Apply(client))
+	assert.Equal(t, 1, server.Applies())
+
+	web01 := server.Find("host", "web01")
+	assert.Equal(t, "10.0.0.1", web01["address"])
+	assert.Equal(t, "Web", web01["alias"])
+	assert.NotContains(t, web01, "notes_url")
+	assert.Nil(t, server.Find("host", "old01"))
+	assert.NotNil(t, server.Find("service", "web01", "PING"))
+	assert.NotNil(t, server.Find("timeperiod", "24x7"))
+
+	// Nothing is left to do once the plan is applied
+	plan, err = manifest.Plan(client, PlanOptions{Prune: true})
+	assert.NoError(t, err)
+	assert.True(t, plan.Empty())
+	assert.NoError(t, plan.Apply(client))
+	assert.Equal(t, 1, server.Applies())
+}
+
+func TestPlan_withoutPrune(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "old01", "address": "10.0.0.8"})
+
+	client := gonagios.NewClient(server.URL, "token123")
+
+	manifest, err := Parse([]byte(testManifest))
+	assert.NoError(t, err)
+
+	plan, err := manifest.Plan(client, PlanOptions{})
+	assert.NoError(t, err)
+
+	_, _, deletes := plan.Count()
+	assert.Equal(t, 0, deletes)
+}
+
+func TestPlan_applyStopsAtFirstFailure(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Fail("host", true)
+
+	client := gonagios.NewClient(server.URL, "token123")
+
+	manifest, err := Parse([]byte(testManifest))
+	assert.NoError(t, err)
+
+	plan, err := manifest.Plan(client, PlanOptions{})
+	assert.NoError(t, err)
+
+	err = plan.Apply(client)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "create host 'web01'")
+	assert.Nil(t, server.Find("service", "web01", "PING"))
+	assert.Equal(t, 0, server.Applies())
+}
+
+func TestPlan_pruneOwnedOnly(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "web01", "address": "10.0.0.1"})
+	server.Add("host", map[string]string{"host_name": "stale1", "_MANAGED_BY": "team-x"})
+	server.Add("host", map[string]string{"host_name": "stale2", "_MANAGED_BY": "team-x"})
+	server.Add("host", map[string]string{"host_name": "handmade"})
+
+	client := gonagios.NewClient(server.URL, "token123")
+	client.Owner = &gonagios.Ownership{Value: "team-x"}
+
+	manifest, err := Parse([]byte("hosts:\n  - host_name: web01\n    address: 10.0.0.1\n"))
+	assert.NoError(t, err)
+
+	_, err = manifest.Plan(client, PlanOptions{Prune: true, MaxDeletions: 1})
+	assert.True(t, errors.Is(err, gonagios.ErrTooManyDeletions))
+
+	plan, err := manifest.Plan(client, PlanOptions{Prune: true, MaxDeletions: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "- delete host stale1\n- delete host stale2\nPlan: 0 to create, 0 to update, 2 to delete\n", plan.String())
+
+	// Drift still reports every object that is not in the manifest
+	drift, err := manifest.Drift(client)
+	assert.NoError(t, err)
+	assert.Len(t, drift.Unmanaged, 3)
+}
@@ -0,0 +1,189 @@
+package gonagios
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler sends a single HTTP request to Nagios and returns the response, like http.RoundTripper
+type Handler func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or alter the requests sent to Nagios
+// Middlewares run around every attempt, so a request that is retried passes through them once per attempt
+type Middleware func(next Handler) Handler
+
+// RequestMetrics describes the outcome of a single request and is passed to the MetricsMiddleware callback
+type RequestMetrics struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Duration   time.Duration
+	Outcome    string
+	Err        error
+}
+
+// Outcomes reported in RequestMetrics
+// Nagios returns most API errors with a HTTP 200, so those are only visible as an error from the client method
+const (
+	OutcomeSuccess      = "success"
+	OutcomeHTTPError    = "http_error"
+	OutcomeNetworkError = "network_error"
+)
+
+// SpanFunc starts a tracing span for a request. It may return a new request, for example with trace
+// headers added, and returns a function that ends the span once the response is received
+type SpanFunc func(request *http.Request) (*http.Request, func(response *http.Response, err error))
+
+// redactedValue replaces secrets in anything we log
+const redactedValue = "REDACTED"
+
+// handler builds the chain of middlewares around the HTTP client
+// The first middleware in the list is the outermost one
+func (client *Client) handler() Handler {
+	// Transport errors quote the URL, so the API key is removed before any middleware, retry callback
+	// or caller sees them
+	next := Handler(func(request *http.Request) (*http.Response, error) {
+		response, err := client.httpClient.Do(request)
+
+		return response, redactError(err)
+	})
+
+	for i := len(client.Middleware) - 1; i >= 0; i-- {
+		next = client.Middleware[i](next)
+	}
+
+	return next
+}
+
+// LoggingMiddleware logs every request as key=value pairs, with the API key removed from the URL
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			response, err := next(request)
+
+			var line strings.Builder
+
+			line.WriteString("method=" + request.Method)
+			line.WriteString(" url=" + strconv.Quote(redactURL(request.URL)))
+
+			if response != nil {
+				line.WriteString(" status=" + strconv.Itoa(response.StatusCode))
+			}
+
+			line.WriteString(" duration=" + time.Since(start).String())
+
+			if err != nil {
+				line.WriteString(" error=" + strconv.Quote(err.Error()))
+			}
+
+			logger.Println(line.String())
+
+			return response, err
+		}
+	}
+}
+
+// MetricsMiddleware reports the latency and outcome of every request to the observe callback
+func MetricsMiddleware(observe func(metrics RequestMetrics)) Middleware {
+	return func(next Handler) Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			response, err := next(request)
+
+			metrics := RequestMetrics{
+				Method:   request.Method,
+				Endpoint: request.URL.Path,
+				Duration: time.Since(start),
+				Outcome:  OutcomeSuccess,
+				Err:      err,
+			}
+
+			if err != nil {
+				metrics.Outcome = OutcomeNetworkError
+			} else {
+				metrics.StatusCode = response.StatusCode
+				if response.StatusCode >= http.StatusBadRequest {
+					metrics.Outcome = OutcomeHTTPError
+				}
+			}
+
+			observe(metrics)
+
+			return response, err
+		}
+	}
+}
+
+// TracingMiddleware wraps every request in a span started by startSpan
+func TracingMiddleware(startSpan SpanFunc) Middleware {
+	return func(next Handler) Handler {
+		return func(request *http.Request) (*http.Response, error) {
+			request, endSpan := startSpan(request)
+
+			response, err := next(request)
+
+			if endSpan != nil {
+				endSpan(response, err)
+			}
+
+			return response, err
+		}
+	}
+}
+
+// redactURL returns the URL as a string with the API key replaced so it is safe to log
+func redactURL(requestURL *url.URL) string {
+	redacted := *requestURL
+	query := redacted.Query()
+
+	if query.Get("apikey") != "" {
+		query.Set("apikey", redactedValue)
+		redacted.RawQuery = query.Encode()
+	}
+
+	return redacted.String()
+}
+
+// redactRawURL replaces the API key in a URL that cannot be parsed, so the rest of it can still be shown
+func redactRawURL(rawURL string) string {
+	base, query, found := strings.Cut(rawURL, "?")
+
+	if !found {
+		return rawURL
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		if strings.HasPrefix(pair, "apikey=") {
+			pairs[i] = "apikey=" + redactedValue
+		}
+	}
+
+	return base + "?" + strings.Join(pairs, "&")
+}
+
+// redactError removes the API key from the URL quoted by a *url.Error, which is what the HTTP client
+// returns when a request cannot be sent, such as when the connection is refused or the URL is malformed
+func redactError(err error) error {
+	var urlError *url.Error
+
+	if !errors.As(err, &urlError) {
+		return err
+	}
+
+	redacted := redactRawURL(urlError.URL)
+
+	if parsed, parseErr := url.Parse(urlError.URL); parseErr == nil {
+		redacted = redactURL(parsed)
+	}
+
+	return &url.Error{Op: urlError.Op, URL: redacted, Err: urlError.Err}
+}
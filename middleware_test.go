@@ -0,0 +1,113 @@
+package gonagios
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware_loggingRedactsToken(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+	defer server.Close()
+
+	var output bytes.Buffer
+	client.Middleware = []Middleware{LoggingMiddleware(log.New(&output, "", 0))}
+
+	_, err := client.get("", client.buildURL("config", "host", http.MethodGet))
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "method=GET")
+	assert.Contains(t, output.String(), "status=200")
+	assert.Contains(t, output.String(), "apikey=REDACTED")
+	assert.NotContains(t, output.String(), "token123")
+}
+
+func TestMiddleware_dialErrorRedactsToken(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := NewClient(server.URL, "token123")
+	client.RetryPolicy.MaxAttempts = 1
+
+	var output bytes.Buffer
+	var metrics []RequestMetrics
+	var attempts []RetryAttempt
+
+	client.Middleware = []Middleware{
+		LoggingMiddleware(log.New(&output, "", 0)),
+		MetricsMiddleware(func(m RequestMetrics) {
+			metrics = append(metrics, m)
+		}),
+	}
+	client.RetryPolicy.OnAttempt = func(attempt RetryAttempt) {
+		attempts = append(attempts, attempt)
+	}
+
+	_, err := client.get("", client.buildURL("config", "host", http.MethodGet))
+
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "token123")
+	assert.Contains(t, output.String(), "error=")
+	assert.Contains(t, output.String(), "apikey=REDACTED")
+	assert.NotContains(t, output.String(), "token123")
+	assert.NotContains(t, metrics[0].Err.Error(), "token123")
+	assert.NotContains(t, attempts[0].Err.Error(), "token123")
+}
+
+func TestMiddleware_chainOrder(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer server.Close()
+
+	client.RetryPolicy.MaxAttempts = 2
+
+	var calls []string
+	var metrics []RequestMetrics
+
+	tracing := TracingMiddleware(func(request *http.Request) (*http.Request, func(*http.Response, error)) {
+		calls = append(calls, "start")
+		return request, func(response *http.Response, err error) {
+			calls = append(calls, "end")
+		}
+	})
+
+	client.Middleware = []Middleware{
+		tracing,
+		MetricsMiddleware(func(m RequestMetrics) {
+			calls = append(calls, "metrics")
+			metrics = append(metrics, m)
+		}),
+	}
+
+	_, err := client.get("", client.buildURL("config", "host", http.MethodGet))
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"start", "metrics", "end", "start", "metrics", "end"}, calls)
+	assert.Equal(t, OutcomeHTTPError, metrics[1].Outcome)
+	assert.Equal(t, http.StatusBadGateway, metrics[1].StatusCode)
+}
+
+func TestMiddleware_malformedURLRedactsToken(t *testing.T) {
+	for _, baseURL := range []string{"http://nagios.local:abc/nagiosxi", "http://nagios.local/nagios%zz"} {
+		client := NewClient(baseURL, "token123")
+
+		_, err := client.get("", client.buildURL("config", "host", http.MethodGet))
+
+		assert.Error(t, err, baseURL)
+		assert.Contains(t, err.Error(), "apikey=REDACTED", baseURL)
+		assert.NotContains(t, err.Error(), "token123", baseURL)
+
+		_, err = client.post(&url.Values{}, client.buildURL("config", "host", http.MethodPost))
+
+		assert.Error(t, err, baseURL)
+		assert.NotContains(t, err.Error(), "token123", baseURL)
+	}
+}
@@ -0,0 +1,332 @@
+package gonagios
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ObjectType is the name of a Nagios object type in the config API
+type ObjectType string
+
+// Object types of the config API
+// Dependencies and escalations are identified by their config_name, and not every XI version exposes them
+const (
+	ObjectHost              ObjectType = "host"
+	ObjectService           ObjectType = "service"
+	ObjectHostGroup         ObjectType = "hostgroup"
+	ObjectServiceGroup      ObjectType = "servicegroup"
+	ObjectCommand           ObjectType = "command"
+	ObjectContact           ObjectType = "contact"
+	ObjectContactGroup      ObjectType = "contactgroup"
+	ObjectTimePeriod        ObjectType = "timeperiod"
+	ObjectHostDependency    ObjectType = "hostdependency"
+	ObjectServiceDependency ObjectType = "servicedependency"
+	ObjectHostEscalation    ObjectType = "hostescalation"
+	ObjectServiceEscalation ObjectType = "serviceescalation"
+)
+
+// ConfigObject is a Nagios object of any type, held as its attributes
+// Lists are held the way Nagios writes them, separated by commas
+type ConfigObject struct {
+	Type       ObjectType        `json:"type"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Names returns the attributes that identify the object in the URL of a PUT or DELETE, in order
+// It returns nil when the object cannot be identified, such as a dependency without a config_name
+func (object *ConfigObject) Names() []string {
+	keys := object.NameKeys()
+	names := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		if object.Attributes[key] == "" {
+			return nil
+		}
+		names = append(names, object.Attributes[key])
+	}
+
+	return names
+}
+
+// Name returns the names that identify the object joined with a space, such as "host1 PING" for a service
+func (object *ConfigObject) Name() string {
+	return strings.Join(object.Names(), " ")
+}
+
+// NameKeys returns the attributes that identify an object of this type, in the order of Names
+func (object *ConfigObject) NameKeys() []string {
+	switch object.Type {
+	case ObjectService:
+		return []string{"host_name", "service_description"}
+	case ObjectHostDependency, ObjectServiceDependency, ObjectHostEscalation, ObjectServiceEscalation:
+		return []string{"config_name"}
+	}
+
+	return []string{string(object.Type) + "_name"}
+}
+
+// namedObject returns an object that holds only the attributes that identify it, see ConfigObject.Names
+func namedObject(objectType ObjectType, names []string) *ConfigObject {
+	object := &ConfigObject{Type: objectType, Attributes: map[string]string{}}
+
+	for i, key := range object.NameKeys() {
+		if i < len(names) {
+			object.Attributes[key] = names[i]
+		}
+	}
+
+	return object
+}
+
+// ConfigObject converts the host to a ConfigObject, for the functions that work with objects of any type
+func (host *Host) ConfigObject() (*ConfigObject, error) {
+	values, err := encodeValues(host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := map[string]string{}
+	for key := range values {
+		attributes[key] = values.Get(key)
+	}
+
+	return &ConfigObject{Type: ObjectHost, Attributes: attributes}, nil
+}
+
+// Host converts a host object to a Host. Attributes the Host struct has no field for are dropped
+func (object *ConfigObject) Host() (*Host, error) {
+	if object.Type != ObjectHost {
+		return nil, errors.New("cannot convert a " + string(object.Type) + " to a host")
+	}
+
+	attributes := make(map[string]interface{}, len(object.Attributes))
+	for key, value := range object.Attributes {
+		attributes[key] = value
+	}
+
+	host := &Host{}
+
+	if err := decodeValues(attributes, host); err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+// ListObjects retrieves every object of a type from Nagios
+func (client *Client) ListObjects(objectType ObjectType) ([]ConfigObject, error) {
+	nagiosURL := client.buildURL(apiType, string(objectType), http.MethodGet)
+
+	body, err := client.get("", nagiosURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rawArray []map[string]interface{}
+
+	err = json.Unmarshal(body, &rawArray)
+
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]ConfigObject, 0, len(rawArray))
+
+	for _, raw := range rawArray {
+		attributes := map[string]string{}
+		for key, value := range raw {
+			attributes[key] = stringifyJSON(value)
+		}
+
+		objects = append(objects, ConfigObject{Type: objectType, Attributes: attributes})
+	}
+
+	return objects, nil
+}
+
+// getObject retrieves a single object by the attributes that identify it, see ConfigObject.Names
+func (client *Client) getObject(objectType ObjectType, names []string) (*ConfigObject, error) {
+	wanted := namedObject(objectType, names)
+
+	nagiosURL := client.buildURL(apiType, string(objectType), http.MethodGet)
+	nagiosURL = addQueryParams(nagiosURL, attributeValues(wanted.Attributes))
+
+	body, err := client.get("", nagiosURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rawArray []map[string]interface{}
+
+	if err := json.Unmarshal(body, &rawArray); err != nil {
+		return nil, err
+	}
+
+	// Not every XI version filters on every attribute, so the names are checked again
+	for _, raw := range rawArray {
+		attributes := map[string]string{}
+		for key, value := range raw {
+			attributes[key] = stringifyJSON(value)
+		}
+
+		object := &ConfigObject{Type: objectType, Attributes: attributes}
+
+		if objectKey(object) == objectKey(wanted) {
+			return object, nil
+		}
+	}
+
+	return nil, notFoundError(string(objectType), wanted.Name())
+}
+
+// createObject creates an object in Nagios XI without applying the configuration
+// Option letters are validated first, see ConfigObject.Validate. The object is marked with the client's ownership marker, if it has one
+// An object that uses templates is sent with force=1, since XI otherwise requires the attributes it inherits from them.
+// When force is set the object is sent as it is with force=1, which is needed to recreate objects exactly as they were
+func (client *Client) createObject(object *ConfigObject, force bool) error {
+	if object.Names() == nil {
+		return errors.New("cannot create a " + string(object.Type) + " without a name")
+	}
+
+	if !force {
+		if err := object.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := client.snapshotBefore(OperationCreate, *object); err != nil {
+		return err
+	}
+
+	nagiosURL := client.buildURL(apiType, string(object.Type), http.MethodPost)
+
+	data := url.Values{}
+	for key, value := range object.Attributes {
+		data.Set(key, value)
+	}
+
+	if force || object.Attributes["use"] != "" {
+		data.Set("force", "1")
+	}
+
+	// A forced create restores an object exactly as it was, so it is not marked as owned
+	if !force {
+		client.Owner.mark(data)
+	}
+
+	_, err := client.post(&data, nagiosURL)
+
+	return err
+}
+
+// replaceObject sends every attribute of an existing object without applying the configuration
+// The object is identified by its attributes, so it cannot be renamed this way
+func (client *Client) replaceObject(object *ConfigObject) error {
+	names := object.Names()
+
+	if names == nil {
+		return errors.New("cannot update a " + string(object.Type) + " without a name")
+	}
+
+	if err := object.Validate(); err != nil {
+		return err
+	}
+
+	if err := client.snapshotBefore(OperationUpdate, *object); err != nil {
+		return err
+	}
+
+	nagiosURL := client.buildURL(apiType, string(object.Type), http.MethodPut, names...)
+
+	params := url.Values{}
+	for key, value := range object.Attributes {
+		params.Set(key, value)
+	}
+
+	_, err := client.put(addQueryParams(nagiosURL, params))
+
+	return err
+}
+
+// deleteObject deletes an object from Nagios without applying the configuration
+func (client *Client) deleteObject(object *ConfigObject) error {
+	names := object.Names()
+
+	if names == nil {
+		return errors.New("cannot delete a " + string(object.Type) + " without a name")
+	}
+
+	if err := client.snapshotBefore(OperationDelete, *object); err != nil {
+		return err
+	}
+
+	nagiosURL := client.buildURL(apiType, string(object.Type), http.MethodDelete, names...)
+
+	// XI also expects the attributes that identify the object in the body
+	data := url.Values{}
+	for _, key := range object.NameKeys() {
+		data.Set(key, object.Attributes[key])
+	}
+
+	_, err := client.delete(&data, nagiosURL)
+
+	return err
+}
+
+// updateObject sends attribute changes to an existing object without applying the configuration
+// The object is identified by names, see ConfigObject.Names
+func (client *Client) updateObject(objectType ObjectType, names []string, fieldChanges []FieldChange) error {
+	// A change to a name attribute renames the object, so the new name has to be absent again after a restore
+	renamed := &ConfigObject{Type: objectType, Attributes: withChanges(namedObject(objectType, names).Attributes, fieldChanges)}
+
+	if err := client.snapshotBefore(OperationUpdate, *namedObject(objectType, names), *renamed); err != nil {
+		return err
+	}
+
+	return client.putObjectChanges(objectType, names, fieldChanges)
+}
+
+// putObjectChanges sends attribute changes to an existing object without taking a snapshot first
+func (client *Client) putObjectChanges(objectType ObjectType, names []string, fieldChanges []FieldChange) error {
+	nagiosURL := client.buildURL(apiType, string(objectType), http.MethodPut, names...)
+	nagiosURL = addQueryParams(nagiosURL, changeValues(fieldChanges))
+
+	_, err := client.put(nagiosURL)
+
+	return err
+}
+
+// withChanges returns a copy of the attributes with the changes made
+func withChanges(attributes map[string]string, fieldChanges []FieldChange) map[string]string {
+	changed := map[string]string{}
+	for key, value := range attributes {
+		changed[key] = value
+	}
+
+	for _, change := range fieldChanges {
+		if change.New == "" {
+			delete(changed, change.Field)
+		} else {
+			changed[change.Field] = change.New
+		}
+	}
+
+	return changed
+}
+
+// reverseChanges returns the changes that undo a list of changes
+func reverseChanges(fieldChanges []FieldChange) []FieldChange {
+	reversed := make([]FieldChange, len(fieldChanges))
+
+	for i, change := range fieldChanges {
+		reversed[i] = FieldChange{Field: change.Field, Old: change.New, New: change.Old}
+	}
+
+	return reversed
+}
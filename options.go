@@ -0,0 +1,291 @@
+package gonagios
+
+import (
+	"errors"
+	"strings"
+)
+
+// HostNotificationOptions is the set of host states that trigger notifications (notification_options)
+type HostNotificationOptions uint
+
+// Host notification options
+const (
+	HostNotifyDown HostNotificationOptions = 1 << iota
+	HostNotifyUnreachable
+	HostNotifyRecovery
+	HostNotifyFlapping
+	HostNotifyDowntime
+	HostNotifyNone
+)
+
+// ServiceNotificationOptions is the set of service states that trigger notifications (notification_options)
+type ServiceNotificationOptions uint
+
+// Service notification options
+const (
+	ServiceNotifyWarning ServiceNotificationOptions = 1 << iota
+	ServiceNotifyUnknown
+	ServiceNotifyCritical
+	ServiceNotifyRecovery
+	ServiceNotifyFlapping
+	ServiceNotifyDowntime
+	ServiceNotifyNone
+)
+
+// HostFlapOptions is the set of host states used for flap detection (flap_detection_options)
+type HostFlapOptions uint
+
+// Host flap detection options
+const (
+	HostFlapUp HostFlapOptions = 1 << iota
+	HostFlapDown
+	HostFlapUnreachable
+	HostFlapNone
+)
+
+// ServiceFlapOptions is the set of service states used for flap detection (flap_detection_options)
+type ServiceFlapOptions uint
+
+// Service flap detection options
+const (
+	ServiceFlapOK ServiceFlapOptions = 1 << iota
+	ServiceFlapWarning
+	ServiceFlapCritical
+	ServiceFlapUnknown
+	ServiceFlapNone
+)
+
+// HostStalkingOptions is the set of host states that are stalked (stalking_options)
+type HostStalkingOptions uint
+
+// Host stalking options
+const (
+	HostStalkUp HostStalkingOptions = 1 << iota
+	HostStalkDown
+	HostStalkUnreachable
+	HostStalkNotifications
+	HostStalkNone
+)
+
+// ServiceStalkingOptions is the set of service states that are stalked (stalking_options)
+type ServiceStalkingOptions uint
+
+// Service stalking options
+const (
+	ServiceStalkOK ServiceStalkingOptions = 1 << iota
+	ServiceStalkWarning
+	ServiceStalkUnknown
+	ServiceStalkCritical
+	ServiceStalkNotifications
+	ServiceStalkNone
+)
+
+// optionKind describes the Nagios letter codes of an option set
+// The letters are in the order of the flags, so letter i is flag 1 << i, and the last letter is always 'n' (none)
+type optionKind struct {
+	name      string
+	letters   string
+	otherName string
+	other     string
+}
+
+var (
+	hostNotificationKind    = optionKind{"host notification", "durfsn", "service", "wucrfsn"}
+	serviceNotificationKind = optionKind{"service notification", "wucrfsn", "host", "durfsn"}
+	hostFlapKind            = optionKind{"host flap detection", "odun", "service", "owcun"}
+	serviceFlapKind         = optionKind{"service flap detection", "owcun", "host", "odun"}
+	hostStalkingKind        = optionKind{"host stalking", "oduNn", "service", "owucNn"}
+	serviceStalkingKind     = optionKind{"service stalking", "owucNn", "host", "oduNn"}
+)
+
+// parse converts a comma separated list of letters to a set of flags
+// Letters only valid for the other object type (such as 'w' for a host) get a more helpful error
+func (kind optionKind) parse(value string) (uint, error) {
+	var options uint
+
+	items := splitList(value)
+
+	for _, item := range items {
+		index := strings.Index(kind.letters, item)
+
+		if len(item) != 1 || index < 0 {
+			if len(item) == 1 && strings.Contains(kind.other, item) {
+				return 0, errors.New("'" + item + "' is a " + kind.otherName + " option, not a " + kind.name + " option")
+			}
+			return 0, errors.New("'" + item + "' is not a " + kind.name + " option (" + strings.Join(strings.Split(kind.letters, ""), ",") + ")")
+		}
+
+		options |= 1 << uint(index)
+	}
+
+	none := uint(1) << uint(len(kind.letters)-1)
+
+	if options&none != 0 && options != none {
+		return 0, errors.New("'n' cannot be combined with other " + kind.name + " options")
+	}
+
+	return options, nil
+}
+
+// format converts a set of flags to a comma separated list of letters
+func (kind optionKind) format(options uint) string {
+	var letters []string
+
+	for i, letter := range kind.letters {
+		if options&(1<<uint(i)) != 0 {
+			letters = append(letters, string(letter))
+		}
+	}
+
+	return strings.Join(letters, ",")
+}
+
+// ParseHostNotificationOptions parses Nagios letter codes such as "d,u,r"
+func ParseHostNotificationOptions(value string) (HostNotificationOptions, error) {
+	options, err := hostNotificationKind.parse(value)
+
+	return HostNotificationOptions(options), err
+}
+
+// String returns the Nagios letter codes, such as "d,u,r"
+func (options HostNotificationOptions) String() string {
+	return hostNotificationKind.format(uint(options))
+}
+
+// MarshalText encodes the options as Nagios letter codes
+func (options HostNotificationOptions) MarshalText() ([]byte, error) {
+	return []byte(options.String()), nil
+}
+
+// UnmarshalText decodes the options from Nagios letter codes
+func (options *HostNotificationOptions) UnmarshalText(text []byte) error {
+	parsed, err := ParseHostNotificationOptions(string(text))
+	*options = parsed
+
+	return err
+}
+
+// ParseServiceNotificationOptions parses Nagios letter codes such as "w,c,r"
+func ParseServiceNotificationOptions(value string) (ServiceNotificationOptions, error) {
+	options, err := serviceNotificationKind.parse(value)
+
+	return ServiceNotificationOptions(options), err
+}
+
+// String returns the Nagios letter codes, such as "w,c,r"
+func (options ServiceNotificationOptions) String() string {
+	return serviceNotificationKind.format(uint(options))
+}
+
+// MarshalText encodes the options as Nagios letter codes
+func (options ServiceNotificationOptions) MarshalText() ([]byte, error) {
+	return []byte(options.String()), nil
+}
+
+// UnmarshalText decodes the options from Nagios letter codes
+func (options *ServiceNotificationOptions) UnmarshalText(text []byte) error {
+	parsed, err := ParseServiceNotificationOptions(string(text))
+	*options = parsed
+
+	return err
+}
+
+// ParseHostFlapOptions parses Nagios letter codes such as "o,d"
+func ParseHostFlapOptions(value string) (HostFlapOptions, error) {
+	options, err := hostFlapKind.parse(value)
+
+	return HostFlapOptions(options), err
+}
+
+// String returns the Nagios letter codes, such as "o,d"
+func (options HostFlapOptions) String() string {
+	return hostFlapKind.format(uint(options))
+}
+
+// MarshalText encodes the options as Nagios letter codes
+func (options HostFlapOptions) MarshalText() ([]byte, error) {
+	return []byte(options.String()), nil
+}
+
+// UnmarshalText decodes the options from Nagios letter codes
+func (options *HostFlapOptions) UnmarshalText(text []byte) error {
+	parsed, err := ParseHostFlapOptions(string(text))
+	*options = parsed
+
+	return err
+}
+
+// ParseServiceFlapOptions parses Nagios letter codes such as "o,w,c"
+func ParseServiceFlapOptions(value string) (ServiceFlapOptions, error) {
+	options, err := serviceFlapKind.parse(value)
+
+	return ServiceFlapOptions(options), err
+}
+
+// String returns the Nagios letter codes, such as "o,w,c"
+func (options ServiceFlapOptions) String() string {
+	return serviceFlapKind.format(uint(options))
+}
+
+// MarshalText encodes the options as Nagios letter codes
+func (options ServiceFlapOptions) MarshalText() ([]byte, error) {
+	return []byte(options.String()), nil
+}
+
+// UnmarshalText decodes the options from Nagios letter codes
+func (options *ServiceFlapOptions) UnmarshalText(text []byte) error {
+	parsed, err := ParseServiceFlapOptions(string(text))
+	*options = parsed
+
+	return err
+}
+
+// ParseHostStalkingOptions parses Nagios letter codes such as "d,u"
+func ParseHostStalkingOptions(value string) (HostStalkingOptions, error) {
+	options, err := hostStalkingKind.parse(value)
+
+	return HostStalkingOptions(options), err
+}
+
+// String returns the Nagios letter codes, such as "d,u"
+func (options HostStalkingOptions) String() string {
+	return hostStalkingKind.format(uint(options))
+}
+
+// MarshalText encodes the options as Nagios letter codes
+func (options HostStalkingOptions) MarshalText() ([]byte, error) {
+	return []byte(options.String()), nil
+}
+
+// UnmarshalText decodes the options from Nagios letter codes
+func (options *HostStalkingOptions) UnmarshalText(text []byte) error {
+	parsed, err := ParseHostStalkingOptions(string(text))
+	*options = parsed
+
+	return err
+}
+
+// ParseServiceStalkingOptions parses Nagios letter codes such as "w,c"
+func ParseServiceStalkingOptions(value string) (ServiceStalkingOptions, error) {
+	options, err := serviceStalkingKind.parse(value)
+
+	return ServiceStalkingOptions(options), err
+}
+
+// String returns the Nagios letter codes, such as "w,c"
+func (options ServiceStalkingOptions) String() string {
+	return serviceStalkingKind.format(uint(options))
+}
+
+// MarshalText encodes the options as Nagios letter codes
+func (options ServiceStalkingOptions) MarshalText() ([]byte, error) {
+	return []byte(options.String()), nil
+}
+
+// UnmarshalText decodes the options from Nagios letter codes
+func (options *ServiceStalkingOptions) UnmarshalText(text []byte) error {
+	parsed, err := ParseServiceStalkingOptions(string(text))
+	*options = parsed
+
+	return err
+}
@@ -0,0 +1,105 @@
+package gonagios
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_parseAndFormat(t *testing.T) {
+	notify, err := ParseHostNotificationOptions("r, d,u")
+
+	assert.NoError(t, err)
+	assert.Equal(t, HostNotifyDown|HostNotifyUnreachable|HostNotifyRecovery, notify)
+	assert.Equal(t, "d,u,r", notify.String())
+
+	service, err := ParseServiceNotificationOptions("w,c,r,f,s")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceNotifyWarning|ServiceNotifyCritical|ServiceNotifyRecovery|ServiceNotifyFlapping|ServiceNotifyDowntime, service)
+
+	stalk, err := ParseHostStalkingOptions("N,o")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "o,N", stalk.String())
+
+	none, err := ParseServiceFlapOptions("n")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ServiceFlapNone, none)
+}
+
+func TestOptions_hostVersusService(t *testing.T) {
+	_, err := ParseHostNotificationOptions("d,w")
+	assert.EqualError(t, err, "'w' is a service option, not a host notification option")
+
+	_, err = ParseServiceFlapOptions("d")
+	assert.EqualError(t, err, "'d' is a host option, not a service flap detection option")
+
+	_, err = ParseHostStalkingOptions("x")
+	assert.EqualError(t, err, "'x' is not a host stalking option (o,d,u,N,n)")
+
+	_, err = ParseHostFlapOptions("o,n")
+	assert.EqualError(t, err, "'n' cannot be combined with other host flap detection options")
+}
+
+func TestOptions_typedHost(t *testing.T) {
+	typed := &TypedHost{}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"notification_options":"d,r","flap_detection_options":["o","d"]}`), typed))
+	assert.Equal(t, HostNotifyDown|HostNotifyRecovery, typed.NotificationOptions)
+	assert.Equal(t, HostFlapUp|HostFlapDown, typed.FlapDetectionOptions)
+
+	host := typed.Host()
+
+	assert.Equal(t, "d,r", host.NotificationOptions)
+	assert.Equal(t, []interface{}{"o", "d"}, host.FlapDetectionOptions)
+
+	body, err := json.Marshal(typed)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"notification_options":"d,r","flap_detection_options":"o,d"}`, string(body))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"notification_options":"w"}`), typed))
+}
+
+func TestOptions_configObjects(t *testing.T) {
+	service := &ConfigObject{Type: ObjectService, Attributes: map[string]string{
+		"host_name":              "web01",
+		"service_description":    "HTTP",
+		"notification_options":   "w,c,r",
+		"flap_detection_options": "o,d",
+	}}
+
+	err := service.Validate()
+
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.EqualError(t, err, "service 'web01 HTTP' is invalid: flap_detection_options: 'd' is a host option, not a service flap detection option")
+
+	contact := &ConfigObject{Type: ObjectContact, Attributes: map[string]string{
+		"contact_name":                 "admin",
+		"host_notification_options":    "d,u,r",
+		"service_notification_options": "w,c,r",
+	}}
+
+	assert.NoError(t, contact.Validate())
+
+	var calls int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"success":"ok"}`))
+	})
+	defer server.Close()
+
+	contact.Attributes["host_notification_options"] = "w"
+
+	err = client.NewConfigSession().NewObject(contact)
+
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,137 @@
+package gonagios
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// DefaultOwnershipVariable is the custom variable that marks owned objects when Ownership.Variable is not set
+const DefaultOwnershipVariable = "_MANAGED_BY"
+
+// Ownership marks the objects a client creates with a custom variable, such as _MANAGED_BY=team-x, so
+// automation can tell the objects it made from the ones made by hand. Set it as Client.Owner
+type Ownership struct {
+	Variable string
+	Value    string
+}
+
+// variable returns the normalized name of the custom variable that marks owned objects
+func (owner *Ownership) variable() string {
+	if owner.Variable == "" {
+		return DefaultOwnershipVariable
+	}
+
+	return normalizeVarName(owner.Variable)
+}
+
+// Owns returns true when the object carries the ownership marker
+func (owner *Ownership) Owns(object *ConfigObject) bool {
+	for name, value := range object.Attributes {
+		if isCustomVariable(name) && normalizeVarName(name) == owner.variable() {
+			return value == owner.Value
+		}
+	}
+
+	return false
+}
+
+// OwnsHost returns true when the host carries the ownership marker
+func (owner *Ownership) OwnsHost(host *Host) bool {
+	value, ok := host.GetVar(owner.variable())
+
+	return ok && value == owner.Value
+}
+
+// mark adds the ownership marker to the values sent to create an object
+// A marker the object already has is kept, so an object is never handed to another owner
+func (owner *Ownership) mark(values url.Values) {
+	if owner == nil || owner.Value == "" {
+		return
+	}
+
+	for name := range values {
+		if isCustomVariable(name) && normalizeVarName(name) == owner.variable() {
+			return
+		}
+	}
+
+	values.Set(owner.variable(), owner.Value)
+}
+
+// ListOwnedObjects retrieves the objects of a type that carry the client's ownership marker
+func (client *Client) ListOwnedObjects(objectType ObjectType) ([]ConfigObject, error) {
+	if client.Owner == nil {
+		return nil, errors.New("the client has no owner set")
+	}
+
+	objects, err := client.ListObjects(objectType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []ConfigObject
+
+	for i := range objects {
+		if client.Owner.Owns(&objects[i]) {
+			owned = append(owned, objects[i])
+		}
+	}
+
+	return owned, nil
+}
+
+// ListOwnedHosts retrieves the hosts that carry the client's ownership marker
+func (client *Client) ListOwnedHosts() ([]Host, error) {
+	if client.Owner == nil {
+		return nil, errors.New("the client has no owner set")
+	}
+
+	hosts, err := client.ListHosts()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var owned []Host
+
+	for i := range hosts {
+		if client.Owner.OwnsHost(&hosts[i]) {
+			owned = append(owned, hosts[i])
+		}
+	}
+
+	return owned, nil
+}
+
+// Prune deletes the owned objects of a type that are not in desired, and applies the configuration once
+// Objects without the client's ownership marker and templates (register 0) are never deleted. When more than
+// maxDeletions objects would be deleted nothing is deleted and the error wraps ErrTooManyDeletions.
+// Zero or less means there is no limit
+func (client *Client) Prune(objectType ObjectType, desired []ConfigObject, maxDeletions int) (*BulkReport, error) {
+	owned, err := client.ListOwnedObjects(objectType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{}
+	for i := range desired {
+		keep[desired[i].Name()] = true
+	}
+
+	var deletes []ConfigObject
+
+	for i := range owned {
+		if owned[i].Names() != nil && !keep[owned[i].Name()] && owned[i].Attributes["register"] != "0" {
+			deletes = append(deletes, owned[i])
+		}
+	}
+
+	if maxDeletions > 0 && len(deletes) > maxDeletions {
+		return nil, fmt.Errorf("pruning would delete %d %s objects, more than the limit of %d: %w", len(deletes), objectType, maxDeletions, ErrTooManyDeletions)
+	}
+
+	return client.BulkDeleteObjects(deletes)
+}
@@ -0,0 +1,100 @@
+package gonagios
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnership_markedOnCreate(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+	client.Owner = &Ownership{Value: "team-x"}
+
+	_, err := client.NewHost(createNamedHostObject("host1"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.NewConfigSession().NewObject(&ConfigObject{
+		Type:       ObjectCommand,
+		Attributes: map[string]string{"command_name": "check_ping", "command_line": "check_ping -H $HOSTADDRESS$", "_managed_by": "team-y"},
+	}))
+
+	assert.Equal(t, "team-x", server.Find("host", "host1")["_MANAGED_BY"])
+	// An object that already has an owner keeps it
+	assert.Equal(t, "team-y", server.Find("command", "check_ping")["_managed_by"])
+	assert.NotContains(t, server.Find("command", "check_ping"), "_MANAGED_BY")
+}
+
+func TestOwnership_listOwned(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "_OWNER": "team-x"})
+	server.Add("host", map[string]string{"host_name": "host2", "_OWNER": "team-y"})
+	server.Add("host", map[string]string{"host_name": "host3"})
+
+	client := NewClient(server.URL, "token123")
+
+	_, err := client.ListOwnedHosts()
+	assert.Error(t, err)
+
+	client.Owner = &Ownership{Variable: "owner", Value: "team-x"}
+
+	hosts, err := client.ListOwnedHosts()
+	assert.NoError(t, err)
+	assert.Len(t, hosts, 1)
+	assert.Equal(t, "host1", hosts[0].HostName)
+
+	objects, err := client.ListOwnedObjects(ObjectHost)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, "host1", objects[0].Name())
+}
+
+func TestOwnership_prune(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "keep", "_MANAGED_BY": "team-x"})
+	server.Add("host", map[string]string{"host_name": "stale1", "_MANAGED_BY": "team-x"})
+	server.Add("host", map[string]string{"host_name": "stale2", "_MANAGED_BY": "team-x"})
+	server.Add("host", map[string]string{"host_name": "handmade"})
+	server.Add("host", map[string]string{"host_name": "template", "register": "0", "_MANAGED_BY": "team-x"})
+
+	client := NewClient(server.URL, "token123")
+	client.Owner = &Ownership{Value: "team-x"}
+
+	desired := []ConfigObject{{Type: ObjectHost, Attributes: map[string]string{"host_name": "keep"}}}
+
+	_, err := client.Prune(ObjectHost, desired, 1)
+
+	assert.True(t, errors.Is(err, ErrTooManyDeletions))
+	assert.Len(t, server.Objects("host"), 5)
+
+	report, err := client.Prune(ObjectHost, desired, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Succeeded(), 2)
+	assert.NotNil(t, server.Find("host", "keep"))
+	assert.NotNil(t, server.Find("host", "handmade"))
+	assert.NotNil(t, server.Find("host", "template"))
+	assert.Len(t, server.Objects("host"), 3)
+	assert.Equal(t, 1, server.Applies())
+}
+
+func TestOwnership_restoreIsNotMarked(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	client := NewClient(server.URL, "token123")
+	client.Owner = &Ownership{Value: "team-x"}
+
+	snapshot := &Snapshot{Objects: []ConfigObject{{Type: ObjectHost, Attributes: map[string]string{"host_name": "host1", "address": "127.0.0.1"}}}}
+
+	assert.NoError(t, client.Restore(snapshot))
+	assert.NotContains(t, server.Find("host", "host1"), "_MANAGED_BY")
+}
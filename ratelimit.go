@@ -0,0 +1,116 @@
+package gonagios
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter caps how fast and how many requests are sent to Nagios at the same time
+// The XI PHP backend does not cope well with hundreds of parallel requests, so a single Limiter
+// can be shared by every method of a client (or by several clients talking to the same instance)
+type Limiter struct {
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	mutex    sync.Mutex
+	inFlight chan struct{}
+}
+
+// NewLimiter creates a token bucket limiter allowing requestsPerSecond on average with bursts of up to burst
+// requests, and at most maxInFlight requests running at once. A value of zero disables the respective limit
+func NewLimiter(requestsPerSecond float64, burst, maxInFlight int) *Limiter {
+	limiter := &Limiter{
+		rate:  requestsPerSecond,
+		burst: float64(burst),
+	}
+
+	if limiter.burst < 1 {
+		limiter.burst = 1
+	}
+
+	limiter.tokens = limiter.burst
+
+	if maxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, maxInFlight)
+	}
+
+	return limiter
+}
+
+// acquire blocks until a request slot is available. Every successful call must be paired with release
+func (limiter *Limiter) acquire(ctx context.Context) error {
+	if limiter == nil || limiter.inFlight == nil {
+		return nil
+	}
+
+	select {
+	case limiter.inFlight <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the request slot taken by acquire
+func (limiter *Limiter) release() {
+	if limiter == nil || limiter.inFlight == nil {
+		return
+	}
+
+	<-limiter.inFlight
+}
+
+// wait blocks until the token bucket allows another request to be sent
+func (limiter *Limiter) wait(ctx context.Context) error {
+	if limiter == nil || limiter.rate <= 0 {
+		return nil
+	}
+
+	delay := limiter.reserve()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Hand the token back since the request will not be sent
+		limiter.mutex.Lock()
+		limiter.tokens++
+		limiter.mutex.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has to wait before using it
+// The bucket is allowed to go negative so callers queue up in the order they arrived
+func (limiter *Limiter) reserve() time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+
+	if !limiter.last.IsZero() {
+		limiter.tokens += now.Sub(limiter.last).Seconds() * limiter.rate
+		if limiter.tokens > limiter.burst {
+			limiter.tokens = limiter.burst
+		}
+	}
+
+	limiter.last = now
+	limiter.tokens--
+
+	if limiter.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-limiter.tokens / limiter.rate * float64(time.Second))
+}
@@ -0,0 +1,123 @@
+package gonagios
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_maxInFlight(t *testing.T) {
+	var current, peak int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		w.Write([]byte(`[]`))
+	})
+	defer server.Close()
+
+	client.Limiter = NewLimiter(0, 0, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.get("", client.buildURL("config", "host", http.MethodGet))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&peak))
+}
+
+func TestLimiter_rate(t *testing.T) {
+	limiter := NewLimiter(100, 1, 0)
+
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+
+	// The bucket is empty, so the next two requests have to wait roughly 10ms and 20ms
+	second := limiter.reserve()
+	third := limiter.reserve()
+
+	assert.InDelta(t, float64(10*time.Millisecond), float64(second), float64(2*time.Millisecond))
+	assert.InDelta(t, float64(20*time.Millisecond), float64(third), float64(2*time.Millisecond))
+}
+
+func TestLimiter_applyConfig(t *testing.T) {
+	var current, peak, gets int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/applyconfig") {
+			atomic.AddInt32(&gets, 1)
+			w.Write([]byte(`[]`))
+			return
+		}
+
+		n := atomic.AddInt32(&current, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		w.Write([]byte(`{"success":"Apply config command has been sent to the backend."}`))
+	})
+	defer server.Close()
+
+	client.ApplyConfigLimiter = NewLimiter(0, 0, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, client.applyConfig())
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.get("", client.buildURL("config", "host", http.MethodGet))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Only one apply config runs at a time, while other requests are not held back by it
+	assert.Equal(t, int32(1), atomic.LoadInt32(&peak))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&gets))
+}
+
+func TestLimiter_applyConfigSameLimiter(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":"Apply config command has been sent to the backend."}`))
+	})
+	defer server.Close()
+
+	limiter := NewLimiter(0, 0, 1)
+	client.Limiter = limiter
+	client.ApplyConfigLimiter = limiter
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := client.applyConfigContext(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, limiter.inFlight, 0)
+}
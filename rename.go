@@ -0,0 +1,243 @@
+package gonagios
+
+import (
+	"errors"
+	"strings"
+)
+
+// hostReference lists the attributes of an object type that hold host names
+type hostReference struct {
+	objectType ObjectType
+	attributes []string
+	// pairs is set for servicegroup members, which alternate host names and service descriptions
+	pairs bool
+	// optional types are skipped when the XI version does not expose them through the config API
+	optional bool
+}
+
+// hostReferences are every attribute that can refer to a host by name
+var hostReferences = []hostReference{
+	{objectType: ObjectHost, attributes: []string{"parents"}},
+	{objectType: ObjectService, attributes: []string{"host_name"}},
+	{objectType: ObjectHostGroup, attributes: []string{"members"}},
+	{objectType: ObjectServiceGroup, attributes: []string{"members"}, pairs: true},
+	{objectType: ObjectHostDependency, attributes: []string{"host_name", "dependent_host_name"}, optional: true},
+	{objectType: ObjectServiceDependency, attributes: []string{"host_name", "dependent_host_name"}, optional: true},
+	{objectType: ObjectHostEscalation, attributes: []string{"host_name"}, optional: true},
+	{objectType: ObjectServiceEscalation, attributes: []string{"host_name"}, optional: true},
+}
+
+// RenameError is returned by RenameHost when the rename could not be completed
+// Every change made before the failure is undone, and RollbackErr is set if that failed as well
+type RenameError struct {
+	OldName     string
+	NewName     string
+	Err         error
+	RollbackErr error
+}
+
+// renameStep is a single change made by RenameHost, kept so it can be undone
+type renameStep struct {
+	objectType ObjectType
+	// names identify the object before the change and after the change, see ConfigObject.Names
+	before  []string
+	after   []string
+	changes []FieldChange
+}
+
+// RenameHost changes the name of a host and rewrites every object that refers to it by name: the parents of
+// other hosts, services, host group and service group members, dependencies and escalations
+// Every reference is found before anything is changed, so the rename is refused without changes when one of
+// them cannot be rewritten. If a write fails the changes already made are undone. The configuration is applied once
+// The snapshot taken when SnapshotDir is set holds every object the rename changes, so Restore undoes all of it
+func (client *Client) RenameHost(oldName, newName string) error {
+	if oldName == newName {
+		return nil
+	}
+
+	steps, err := client.planRename(oldName, newName)
+
+	if err != nil {
+		return &RenameError{OldName: oldName, NewName: newName, Err: err}
+	}
+
+	if err := client.snapshotBefore(OperationUpdate, renameObjects(steps)...); err != nil {
+		return &RenameError{OldName: oldName, NewName: newName, Err: err}
+	}
+
+	for i, step := range steps {
+		if err := client.putObjectChanges(step.objectType, step.before, step.changes); err != nil {
+			return &RenameError{OldName: oldName, NewName: newName, Err: err, RollbackErr: client.undoRename(steps[:i])}
+		}
+	}
+
+	if err := client.applyConfig(); err != nil {
+		return &RenameError{OldName: oldName, NewName: newName, Err: err, RollbackErr: client.undoRename(steps)}
+	}
+
+	return nil
+}
+
+// planRename finds every change needed to rename a host, starting with the host itself
+func (client *Client) planRename(oldName, newName string) ([]renameStep, error) {
+	check := &validator{}
+	check.required("host_name", newName)
+	check.objectName("host_name", newName)
+
+	if err := check.result(objectType, newName); err != nil {
+		return nil, err
+	}
+
+	if _, err := client.GetHost(oldName); err != nil {
+		return nil, err
+	}
+
+	_, err := client.GetHost(newName)
+
+	if err == nil {
+		return nil, errors.New("host '" + newName + "' already exists")
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	steps := []renameStep{{
+		objectType: ObjectHost,
+		before:     []string{oldName},
+		after:      []string{newName},
+		changes:    []FieldChange{{Field: "host_name", Old: oldName, New: newName}},
+	}}
+
+	var unrewritable []string
+
+	for _, reference := range hostReferences {
+		objects, err := client.ListObjects(reference.objectType)
+
+		if reference.optional && errors.Is(err, ErrUnsupported) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range objects {
+			object := &objects[i]
+
+			var changes []FieldChange
+			for _, attribute := range reference.attributes {
+				value, changed := renameInList(object.Attributes[attribute], oldName, newName, reference.pairs)
+				if changed {
+					changes = append(changes, FieldChange{Field: attribute, Old: object.Attributes[attribute], New: value})
+				}
+			}
+
+			if len(changes) == 0 {
+				continue
+			}
+
+			before := object.Names()
+
+			if before == nil {
+				unrewritable = append(unrewritable, string(object.Type))
+				continue
+			}
+
+			renamed := &ConfigObject{Type: object.Type, Attributes: withChanges(object.Attributes, changes)}
+
+			steps = append(steps, renameStep{
+				objectType: object.Type,
+				before:     before,
+				after:      renamed.Names(),
+				changes:    changes,
+			})
+		}
+	}
+
+	if len(unrewritable) > 0 {
+		return nil, errors.New("cannot rewrite references to host '" + oldName + "' in objects without a name: " + strings.Join(unrewritable, ", "))
+	}
+
+	return steps, nil
+}
+
+// renameObjects identifies every object the steps of a rename change, under their names before and after
+// the change, starting with the host
+func renameObjects(steps []renameStep) []ConfigObject {
+	var objects []ConfigObject
+
+	for _, step := range steps {
+		objects = append(objects, *namedObject(step.objectType, step.before), *namedObject(step.objectType, step.after))
+	}
+
+	return objects
+}
+
+// undoRename reverses the steps of a rename, last step first
+// Every step is attempted even if one fails, and the first error is returned
+func (client *Client) undoRename(steps []renameStep) error {
+	var firstErr error
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+
+		err := client.putObjectChanges(step.objectType, step.after, reverseChanges(step.changes))
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// renameInList replaces a host name in a comma separated list, including negated entries such as !host1
+// When pairs is set only every other item is a host name, as in servicegroup members
+func renameInList(value, oldName, newName string, pairs bool) (string, bool) {
+	if value == "" {
+		return value, false
+	}
+
+	items := strings.Split(value, ",")
+	changed := false
+
+	for i, item := range items {
+		if pairs && i%2 == 1 {
+			continue
+		}
+
+		switch strings.TrimSpace(item) {
+		case oldName:
+			items[i] = newName
+			changed = true
+		case "!" + oldName:
+			items[i] = "!" + newName
+			changed = true
+		}
+	}
+
+	return strings.Join(items, ","), changed
+}
+
+// Error describes why the rename failed and whether it could be undone
+func (renameError *RenameError) Error() string {
+	message := "renaming host '" + renameError.OldName + "' to '" + renameError.NewName + "' failed: " + renameError.Err.Error()
+
+	if renameError.RollbackErr != nil {
+		message += "; undoing the changes failed: " + renameError.RollbackErr.Error()
+	}
+
+	return message
+}
+
+// Unwrap returns the error that stopped the rename and the rollback error, so they can be checked with errors.Is
+func (renameError *RenameError) Unwrap() []error {
+	errs := []error{renameError.Err}
+
+	if renameError.RollbackErr != nil {
+		errs = append(errs, renameError.RollbackErr)
+	}
+
+	return errs
+}
@@ -0,0 +1,121 @@
+package gonagios
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+// addRenameFixtures adds host1 and every kind of object that refers to it
+func addRenameFixtures(server *fakenagios.Server) {
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+	server.Add("host", map[string]string{"host_name": "host2", "address": "127.0.0.2", "parents": "host1"})
+	server.Add("service", map[string]string{"host_name": "host1", "service_description": "PING"})
+	server.Add("service", map[string]string{"host_name": "host2,!host1", "service_description": "HTTP"})
+	server.Add("hostgroup", map[string]string{"hostgroup_name": "web", "members": "host1,host2"})
+	server.Add("servicegroup", map[string]string{"servicegroup_name": "pings", "members": "host1,PING,host2,host1"})
+	server.Add("hostdependency", map[string]string{"config_name": "dep1", "host_name": "host2", "dependent_host_name": "host1"})
+	server.Add("hostescalation", map[string]string{"config_name": "esc1", "host_name": "host1"})
+}
+
+func TestRename_renameHost(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	addRenameFixtures(server)
+
+	client := NewClient(server.URL, "token123")
+
+	assert.NoError(t, client.RenameHost("host1", "renamed"))
+
+	assert.Nil(t, server.Find("host", "host1"))
+	assert.NotNil(t, server.Find("host", "renamed"))
+	assert.Equal(t, "renamed", server.Find("host", "host2")["parents"])
+	assert.NotNil(t, server.Find("service", "renamed", "PING"))
+	assert.NotNil(t, server.Find("service", "host2,!renamed", "HTTP"))
+	assert.Equal(t, "renamed,host2", server.Find("hostgroup", "web")["members"])
+	// Only host names are rewritten in servicegroup members, not a service that happens to share the name
+	assert.Equal(t, "renamed,PING,host2,host1", server.Find("servicegroup", "pings")["members"])
+	assert.Equal(t, "renamed", server.Find("hostdependency", "dep1")["dependent_host_name"])
+	assert.Equal(t, "renamed", server.Find("hostescalation", "esc1")["host_name"])
+	assert.Equal(t, 1, server.Applies())
+}
+
+func TestRename_skipsUnsupportedTypes(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	addRenameFixtures(server)
+	server.Unsupported("hostescalation")
+	server.Unsupported("serviceescalation")
+
+	client := NewClient(server.URL, "token123")
+
+	assert.NoError(t, client.RenameHost("host1", "renamed"))
+	assert.Equal(t, "renamed", server.Find("hostdependency", "dep1")["dependent_host_name"])
+}
+
+func TestRename_rollsBackOnFailure(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	addRenameFixtures(server)
+	server.Fail("hostgroup", true)
+
+	client := NewClient(server.URL, "token123")
+
+	err := client.RenameHost("host1", "renamed")
+
+	var renameError *RenameError
+	assert.True(t, errors.As(err, &renameError))
+	assert.NoError(t, renameError.RollbackErr)
+
+	assert.NotNil(t, server.Find("host", "host1"))
+	assert.Nil(t, server.Find("host", "renamed"))
+	assert.Equal(t, "host1", server.Find("host", "host2")["parents"])
+	assert.NotNil(t, server.Find("service", "host1", "PING"))
+	assert.Equal(t, 0, server.Applies())
+}
+
+func TestRename_refusesUnrewritableReferences(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	addRenameFixtures(server)
+	server.Add("servicedependency", map[string]string{"host_name": "host1", "dependent_host_name": "host2"})
+
+	client := NewClient(server.URL, "token123")
+
+	err := client.RenameHost("host1", "renamed")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "servicedependency")
+	assert.Equal(t, 0, server.Writes())
+}
+
+func TestRename_refusesExistingName(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	addRenameFixtures(server)
+
+	client := NewClient(server.URL, "token123")
+
+	assert.Error(t, client.RenameHost("host1", "host2"))
+	assert.True(t, errors.Is(client.RenameHost("missing", "renamed"), ErrNotFound))
+	assert.True(t, errors.Is(client.RenameHost("host1", "bad,name"), ErrValidation))
+	assert.Equal(t, 0, server.Writes())
+}
+
+func TestRename_renameInList(t *testing.T) {
+	value, changed := renameInList("host1,host10,!host1", "host1", "new", false)
+
+	assert.True(t, changed)
+	assert.Equal(t, "new,host10,!new", value)
+
+	_, changed = renameInList("host10", "host1", "new", false)
+
+	assert.False(t, changed)
+}
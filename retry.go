@@ -0,0 +1,228 @@
+package gonagios
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a transient error are retried
+// Nagios drops connections and answers with 502/503 while the core restarts after applyConfig,
+// so by default the client retries idempotent requests with exponential backoff and jitter
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles after every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts
+	MaxDelay time.Duration
+	// OnAttempt is called after every attempt, whether it succeeded or not
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// RetryAttempt describes the outcome of a single attempt and is passed to RetryPolicy.OnAttempt
+type RetryAttempt struct {
+	Attempt    int
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Err        error
+	Retry      bool
+	Delay      time.Duration
+}
+
+// retryableStatusCodes are the HTTP status codes Nagios (or the web server in front of it)
+// returns while the backend is restarting
+var retryableStatusCodes = map[int]bool{
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+// doWithRetry sends the HTTP request and retries it according to the client's retry policy
+// GET, PUT and DELETE are retried on any transient failure. POST is only retried when the error
+// proves the request never reached Nagios, since creating an object twice is not safe
+func (client *Client) doWithRetry(httpRequest *http.Request) (*http.Response, error) {
+	policy := client.RetryPolicy
+
+	maxAttempts := 1
+	if policy != nil && policy.MaxAttempts > 1 {
+		maxAttempts = policy.MaxAttempts
+	}
+
+	handler := client.handler()
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := rewindBody(httpRequest); err != nil {
+				return nil, err
+			}
+		}
+
+		// Every attempt counts against the rate limit, including retries
+		if err := client.Limiter.wait(httpRequest.Context()); err != nil {
+			return nil, err
+		}
+
+		response, err := handler(httpRequest)
+
+		retry := attempt < maxAttempts && shouldRetry(httpRequest, response, err)
+
+		var delay time.Duration
+		if retry {
+			delay = policy.backoff(attempt)
+		}
+
+		if policy != nil && policy.OnAttempt != nil {
+			info := RetryAttempt{
+				Attempt:  attempt,
+				Method:   httpRequest.Method,
+				Endpoint: httpRequest.URL.Path,
+				Err:      err,
+				Retry:    retry,
+				Delay:    delay,
+			}
+			if response != nil {
+				info.StatusCode = response.StatusCode
+			}
+			policy.OnAttempt(info)
+		}
+
+		if !retry {
+			return response, err
+		}
+
+		// Drain and close the body so the underlying connection can be reused by the next attempt
+		if response != nil {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-httpRequest.Context().Done():
+			timer.Stop()
+			return nil, httpRequest.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt using exponential backoff with jitter
+// Half of the delay is fixed and the other half is random so parallel clients do not retry in lockstep
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// shouldRetry determines if a failed attempt is safe and worthwhile to retry
+func shouldRetry(httpRequest *http.Request, response *http.Response, err error) bool {
+	// Without a way to rebuild the body we cannot send the request again
+	if httpRequest.Body != nil && httpRequest.Body != http.NoBody && httpRequest.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		// The request context was cancelled or timed out, so the caller is no longer waiting
+		if httpRequest.Context().Err() != nil {
+			return false
+		}
+
+		if requestNeverSent(err) {
+			return true
+		}
+
+		return isIdempotent(httpRequest.Method) && isTransientError(err)
+	}
+
+	return isIdempotent(httpRequest.Method) && retryableStatusCodes[response.StatusCode]
+}
+
+// isIdempotent returns true for HTTP methods that can be repeated without changing the outcome
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// requestNeverSent returns true when the error proves no bytes of the request reached the server,
+// such as a failed DNS lookup or a refused connection
+func requestNeverSent(err error) bool {
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return true
+	}
+
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isTransientError returns true for network errors that are likely to go away on their own
+func isTransientError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// rewindBody replaces the request body with a fresh copy so it can be sent again
+func rewindBody(httpRequest *http.Request) error {
+	if httpRequest.GetBody == nil {
+		return nil
+	}
+
+	body, err := httpRequest.GetBody()
+
+	if err != nil {
+		return err
+	}
+
+	httpRequest.Body = body
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package gonagios
+
+import (
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry_getRetriesUnavailable(t *testing.T) {
+	var calls int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"product":"nagiosxi"}`))
+	})
+	defer server.Close()
+
+	var attempts []RetryAttempt
+	client.RetryPolicy.OnAttempt = func(attempt RetryAttempt) {
+		attempts = append(attempts, attempt)
+	}
+
+	body, err := client.get("", client.buildURL("system", "info", http.MethodGet))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"product":"nagiosxi"}`, string(body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Len(t, attempts, 3)
+	assert.True(t, attempts[0].Retry)
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[0].StatusCode)
+	assert.False(t, attempts[2].Retry)
+}
+
+func TestRetry_postNotRetriedOnceSent(t *testing.T) {
+	var calls int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"unavailable"}`))
+	})
+	defer server.Close()
+
+	_, err := client.post(&url.Values{}, client.buildURL("config", "host", http.MethodPost))
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_postRetriedWhenNeverSent(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {})
+	// Close the server straight away so every connection attempt is refused
+	server.Close()
+
+	var attempts int
+	client.RetryPolicy.OnAttempt = func(attempt RetryAttempt) {
+		attempts++
+	}
+
+	_, err := client.post(&url.Values{}, client.buildURL("config", "host", http.MethodPost))
+
+	assert.Error(t, err)
+	assert.Equal(t, client.RetryPolicy.MaxAttempts, attempts)
+}
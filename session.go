@@ -0,0 +1,239 @@
+package gonagios
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Operation is the kind of change made to a Nagios object
+type Operation string
+
+// Operations recorded in a WriteResult
+const (
+	OperationCreate Operation = "create"
+	OperationUpdate Operation = "update"
+	OperationDelete Operation = "delete"
+)
+
+// ErrSessionCommitted is returned when a write is made on a ConfigSession that was already committed
+var ErrSessionCommitted = errors.New("config session already committed")
+
+// WriteResult records the outcome of a single write made in a ConfigSession
+type WriteResult struct {
+	Operation  Operation
+	ObjectType string
+	Name       string
+	Err        error
+}
+
+// ConfigSession batches configuration changes so Nagios is only restarted once
+// Writes are sent to XI straight away but the configuration is not applied until Commit is called.
+// A session is safe to use from several goroutines
+type ConfigSession struct {
+	client    *Client
+	mutex     sync.Mutex
+	results   []WriteResult
+	committed bool
+	// inFlight counts the writes that started before Commit, so Commit can wait for them to be recorded
+	inFlight sync.WaitGroup
+}
+
+// CommitError is returned by Commit when one or more writes failed or the configuration could not be applied
+type CommitError struct {
+	Failed   []WriteResult
+	ApplyErr error
+}
+
+// NewConfigSession starts a session that applies the configuration once, when Commit is called
+func (client *Client) NewConfigSession() *ConfigSession {
+	return &ConfigSession{
+		client: client,
+	}
+}
+
+// NewHost creates a host object in Nagios XI without applying the configuration
+func (session *ConfigSession) NewHost(host *Host) error {
+	return session.write(OperationCreate, objectType, host.HostName, func() error {
+		_, err := session.client.createHost(host, false)
+		return err
+	})
+}
+
+// UpdateHost updates attributes of an existing host in Nagios without applying the configuration
+func (session *ConfigSession) UpdateHost(host *Host, currentValue interface{}) error {
+	return session.write(OperationUpdate, objectType, currentValue.(string), func() error {
+		return session.client.updateHost(host, currentValue.(string))
+	})
+}
+
+// PatchHost changes only the attributes of an existing host that differ, see Client.PatchHost
+// A host that already matches is not recorded as a write, so on its own it does not cause the configuration to be applied
+func (session *ConfigSession) PatchHost(name string, changes *Host, clearFields ...string) (bool, error) {
+	return session.writeChanges(OperationUpdate, objectType, name, func() (bool, error) {
+		fieldChanges, err := session.client.patchHost(name, changes, clearFields...)
+		return len(fieldChanges) > 0, err
+	})
+}
+
+// DeleteHost deletes a host from Nagios without applying the configuration
+func (session *ConfigSession) DeleteHost(name string) error {
+	return session.write(OperationDelete, objectType, name, func() error {
+		_, err := session.client.deleteHost(name)
+		return err
+	})
+}
+
+// NewObject creates an object of any type without applying the configuration
+func (session *ConfigSession) NewObject(object *ConfigObject) error {
+	return session.write(OperationCreate, string(object.Type), object.Name(), func() error {
+		return session.client.createObject(object, false)
+	})
+}
+
+// UpdateObject sends every attribute of an existing object of any type without applying the configuration
+func (session *ConfigSession) UpdateObject(object *ConfigObject) error {
+	return session.write(OperationUpdate, string(object.Type), object.Name(), func() error {
+		return session.client.replaceObject(object)
+	})
+}
+
+// DeleteObject deletes an object of any type without applying the configuration
+func (session *ConfigSession) DeleteObject(object *ConfigObject) error {
+	return session.write(OperationDelete, string(object.Type), object.Name(), func() error {
+		return session.client.deleteObject(object)
+	})
+}
+
+// Results returns the outcome of every write made in the session so far
+func (session *ConfigSession) Results() []WriteResult {
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	results := make([]WriteResult, len(session.results))
+	copy(results, session.results)
+
+	return results
+}
+
+// Commit applies the configuration a single time for every write made in the session
+// Writes that are still being sent are waited for, writes started afterwards return ErrSessionCommitted
+// When no write succeeded there is nothing to apply and Nagios is not restarted
+func (session *ConfigSession) Commit() error {
+	session.mutex.Lock()
+
+	if session.committed {
+		session.mutex.Unlock()
+		return ErrSessionCommitted
+	}
+
+	session.committed = true
+	session.mutex.Unlock()
+
+	session.inFlight.Wait()
+
+	session.mutex.Lock()
+
+	commitError := &CommitError{}
+	succeeded := 0
+
+	for _, result := range session.results {
+		if result.Err != nil {
+			commitError.Failed = append(commitError.Failed, result)
+		} else {
+			succeeded++
+		}
+	}
+
+	session.mutex.Unlock()
+
+	if succeeded > 0 {
+		commitError.ApplyErr = session.client.applyConfig()
+	}
+
+	if commitError.Failed != nil || commitError.ApplyErr != nil {
+		return commitError
+	}
+
+	return nil
+}
+
+// write runs a single write and records its outcome
+func (session *ConfigSession) write(operation Operation, objectType, name string, send func() error) error {
+	_, err := session.writeChanges(operation, objectType, name, func() (bool, error) {
+		return true, send()
+	})
+
+	return err
+}
+
+// writeChanges runs a write that may find nothing to change
+// Its outcome is only recorded when it changed something or failed
+func (session *ConfigSession) writeChanges(operation Operation, objectType, name string, send func() (bool, error)) (bool, error) {
+	// The write is counted under the lock, so Commit either sees it in flight or it sees the session committed
+	session.mutex.Lock()
+
+	if session.committed {
+		session.mutex.Unlock()
+		return false, ErrSessionCommitted
+	}
+
+	session.inFlight.Add(1)
+	session.mutex.Unlock()
+
+	defer session.inFlight.Done()
+
+	changed, err := send()
+
+	if !changed && err == nil {
+		return false, nil
+	}
+
+	session.mutex.Lock()
+	session.results = append(session.results, WriteResult{
+		Operation:  operation,
+		ObjectType: objectType,
+		Name:       name,
+		Err:        err,
+	})
+	session.mutex.Unlock()
+
+	return changed, err
+}
+
+// Error lists every failed write and the apply error, if any
+func (commitError *CommitError) Error() string {
+	var message strings.Builder
+
+	if len(commitError.Failed) > 0 {
+		message.WriteString(strconv.Itoa(len(commitError.Failed)) + " write(s) failed")
+		for _, result := range commitError.Failed {
+			message.WriteString("; " + string(result.Operation) + " " + result.ObjectType + " '" + result.Name + "': " + result.Err.Error())
+		}
+	}
+
+	if commitError.ApplyErr != nil {
+		if message.Len() > 0 {
+			message.WriteString("; ")
+		}
+		message.WriteString("apply config failed: " + commitError.ApplyErr.Error())
+	}
+
+	return message.String()
+}
+
+// Unwrap returns the errors of every failed write and the apply error so they can be checked with errors.Is
+func (commitError *CommitError) Unwrap() []error {
+	var errs []error
+
+	for _, result := range commitError.Failed {
+		errs = append(errs, result.Err)
+	}
+
+	if commitError.ApplyErr != nil {
+		errs = append(errs, commitError.ApplyErr)
+	}
+
+	return errs
+}
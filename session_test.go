@@ -0,0 +1,105 @@
+package gonagios
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSession_commitAppliesOnce(t *testing.T) {
+	var applies int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/applyconfig") {
+			atomic.AddInt32(&applies, 1)
+		}
+		r.ParseForm()
+		if r.Form.Get("host_name") == "bad" {
+			w.Write([]byte(`{"error":"Missing required variables","missing":["address"]}`))
+			return
+		}
+		w.Write([]byte(`{"success":"ok"}`))
+	})
+	defer server.Close()
+
+	session := client.NewConfigSession()
+
+	assert.NoError(t, session.NewHost(createNamedHostObject("host1")))
+	assert.NoError(t, session.NewHost(createNamedHostObject("host2")))
+	assert.Error(t, session.NewHost(createNamedHostObject("bad")))
+	assert.NoError(t, session.DeleteHost("host3"))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&applies))
+
+	err := session.Commit()
+
+	var commitError *CommitError
+	assert.True(t, errors.As(err, &commitError))
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Len(t, commitError.Failed, 1)
+	assert.Equal(t, "bad", commitError.Failed[0].Name)
+	assert.NoError(t, commitError.ApplyErr)
+	assert.Len(t, session.Results(), 4)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&applies))
+
+	assert.Equal(t, ErrSessionCommitted, session.DeleteHost("host1"))
+	assert.Equal(t, ErrSessionCommitted, session.Commit())
+}
+
+func TestConfigSession_nothingToApply(t *testing.T) {
+	var calls int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+	defer server.Close()
+
+	assert.NoError(t, client.NewConfigSession().Commit())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestConfigSession_commitWaitsForWrites(t *testing.T) {
+	var writes, writesBeforeApply int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/applyconfig") {
+			atomic.StoreInt32(&writesBeforeApply, atomic.LoadInt32(&writes))
+		} else {
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&writes, 1)
+		}
+		w.Write([]byte(`{"success":"ok"}`))
+	})
+	defer server.Close()
+
+	session := client.NewConfigSession()
+
+	var accepted int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := session.DeleteHost("host" + strconv.Itoa(i)); err != ErrSessionCommitted {
+				assert.NoError(t, err)
+				atomic.AddInt32(&accepted, 1)
+			}
+		}(i)
+	}
+
+	// Commit while some writes are still being sent
+	time.Sleep(2 * time.Millisecond)
+	commitErr := session.Commit()
+	wg.Wait()
+
+	assert.NoError(t, commitErr)
+	assert.Len(t, session.Results(), int(atomic.LoadInt32(&accepted)))
+	assert.Equal(t, atomic.LoadInt32(&accepted), atomic.LoadInt32(&writesBeforeApply))
+}
@@ -0,0 +1,300 @@
+package gonagios
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Snapshot is a copy of configuration taken before a change, which Restore uses to undo it
+// Objects are identified by their type and name attributes, see ConfigObject.NameKeys
+type Snapshot struct {
+	CreatedAt time.Time `json:"created_at"`
+	// Complete is true when the snapshot holds every object, so Restore also deletes objects created since
+	Complete bool           `json:"complete"`
+	Objects  []ConfigObject `json:"objects"`
+	// Absent lists objects that did not exist when the snapshot was taken and are deleted by Restore
+	// Only their name attributes are kept
+	Absent []ConfigObject `json:"absent,omitempty"`
+}
+
+// snapshotTypes are the object types a complete snapshot holds, in the order Restore creates them
+var snapshotTypes = []ObjectType{
+	ObjectTimePeriod,
+	ObjectCommand,
+	ObjectContact,
+	ObjectContactGroup,
+	ObjectHostGroup,
+	ObjectHost,
+	ObjectServiceGroup,
+	ObjectService,
+	ObjectHostDependency,
+	ObjectServiceDependency,
+	ObjectHostEscalation,
+	ObjectServiceEscalation,
+}
+
+// unsafeFileChars matches everything we do not want in a snapshot file name
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Snapshot exports the given objects, which only need their type and name attributes, or every object
+// of every type when none are given. Objects that do not exist are recorded as absent, so restoring the
+// snapshot deletes them again. A complete snapshot leaves out templates, which have no name to restore
+// them by, and the types the XI version does not expose
+func (client *Client) Snapshot(objects ...ConfigObject) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		CreatedAt: time.Now(),
+	}
+
+	if len(objects) == 0 {
+		snapshot.Complete = true
+
+		for _, objectType := range snapshotTypes {
+			current, err := client.ListObjects(objectType)
+
+			if errors.Is(err, ErrUnsupported) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, object := range current {
+				if object.Names() != nil {
+					snapshot.Objects = append(snapshot.Objects, object)
+				}
+			}
+		}
+
+		return snapshot, nil
+	}
+
+	seen := map[string]bool{}
+
+	for i := range objects {
+		names := objects[i].Names()
+		key := string(objects[i].Type) + "\x00" + objectKey(&objects[i])
+
+		if names == nil || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		object, err := client.getObject(objects[i].Type, names)
+
+		if errors.Is(err, ErrNotFound) {
+			snapshot.Absent = append(snapshot.Absent, *namedObject(objects[i].Type, names))
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.Objects = append(snapshot.Objects, *object)
+	}
+
+	return snapshot, nil
+}
+
+// Save writes the snapshot to a JSON file
+func (snapshot *Snapshot) Save(path string) error {
+	body, err := json.MarshalIndent(snapshot, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, body, 0600)
+}
+
+// LoadSnapshot reads a snapshot written by Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	body, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{}
+
+	err = json.Unmarshal(body, snapshot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Restore returns Nagios to the state recorded in the snapshot
+// Objects in the snapshot are recreated or updated, absent objects are deleted, and the configuration is applied once.
+// Objects that have not changed since the snapshot are left alone. Objects are created in the order of their
+// types, such as commands before the hosts that use them, and deleted in reverse
+func (client *Client) Restore(snapshot *Snapshot) error {
+	session := client.NewConfigSession()
+
+	var deletes []ConfigObject
+
+	for _, objectType := range snapshotTypes {
+		objects := snapshot.ofType(objectType, snapshot.Objects)
+		absent := snapshot.ofType(objectType, snapshot.Absent)
+
+		if len(objects) == 0 && len(absent) == 0 && !snapshot.Complete {
+			continue
+		}
+
+		current, err := client.ListObjects(objectType)
+
+		if errors.Is(err, ErrUnsupported) && len(objects) == 0 {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		existing := map[string]*ConfigObject{}
+		for i := range current {
+			if current[i].Names() != nil {
+				existing[objectKey(&current[i])] = &current[i]
+			}
+		}
+
+		restored := map[string]bool{}
+
+		for _, object := range objects {
+			object := object
+			restored[objectKey(object)] = true
+
+			if currentObject, ok := existing[objectKey(object)]; ok {
+				// Only what changed since the snapshot is sent, and attributes added since then are cleared
+				session.writeChanges(OperationUpdate, string(objectType), object.Name(), func() (bool, error) {
+					fieldChanges := restoreChanges(currentObject, object)
+
+					if len(fieldChanges) == 0 {
+						return false, nil
+					}
+
+					return true, client.updateObject(objectType, object.Names(), fieldChanges)
+				})
+			} else {
+				// Recreate the object exactly as it was, even if it relies on templates for required attributes
+				session.write(OperationCreate, string(objectType), object.Name(), func() error {
+					return client.createObject(object, true)
+				})
+			}
+		}
+
+		for _, object := range absent {
+			if existing[objectKey(object)] != nil && !restored[objectKey(object)] {
+				deletes = append(deletes, *existing[objectKey(object)])
+				restored[objectKey(object)] = true
+			}
+		}
+
+		if snapshot.Complete {
+			for i := range current {
+				if current[i].Names() != nil && !restored[objectKey(&current[i])] {
+					deletes = append(deletes, current[i])
+				}
+			}
+		}
+	}
+
+	for i := len(deletes) - 1; i >= 0; i-- {
+		session.DeleteObject(&deletes[i])
+	}
+
+	return session.Commit()
+}
+
+// objectKey identifies an object among the objects of its type, even when its names contain spaces
+func objectKey(object *ConfigObject) string {
+	return strings.Join(object.Names(), "\x00")
+}
+
+// ofType returns the objects of a type from one of the lists of the snapshot
+func (snapshot *Snapshot) ofType(objectType ObjectType, objects []ConfigObject) []*ConfigObject {
+	var matching []*ConfigObject
+
+	for i := range objects {
+		if objects[i].Type == objectType && objects[i].Names() != nil {
+			matching = append(matching, &objects[i])
+		}
+	}
+
+	return matching
+}
+
+// restoreChanges returns the changes that turn the current version of an object back into the one in the snapshot
+func restoreChanges(current, snapshot *ConfigObject) []FieldChange {
+	currentValues := attributeValues(current.Attributes)
+	snapshotValues := attributeValues(snapshot.Attributes)
+
+	for _, field := range removedFields(currentValues, snapshotValues) {
+		snapshotValues.Set(field, "")
+	}
+
+	return diffValues(currentValues, snapshotValues)
+}
+
+// attributeValues converts the attributes of an object to URL values, to compare them with diffValues
+func attributeValues(attributes map[string]string) url.Values {
+	values := url.Values{}
+	for key, value := range attributes {
+		values.Set(key, value)
+	}
+
+	return values
+}
+
+// hostObjects identifies hosts by name, for snapshotBefore. Empty names are left out
+func hostObjects(names ...string) []ConfigObject {
+	var objects []ConfigObject
+
+	for _, name := range names {
+		if name != "" {
+			objects = append(objects, *namedObject(ObjectHost, []string{name}))
+		}
+	}
+
+	return objects
+}
+
+// snapshotBefore saves a snapshot to the client's SnapshotDir before a change is made
+// Depending on SnapshotAll the snapshot holds every object or only the objects the change affects
+func (client *Client) snapshotBefore(operation Operation, objects ...ConfigObject) error {
+	if client.SnapshotDir == "" {
+		return nil
+	}
+
+	if client.SnapshotAll {
+		objects = nil
+	}
+
+	snapshot, err := client.Snapshot(objects...)
+
+	if err != nil {
+		return err
+	}
+
+	fileName := snapshot.CreatedAt.UTC().Format("20060102T150405.000000000Z") + "-" + string(operation)
+	if len(objects) > 0 {
+		fileName += "-" + string(objects[0].Type) + "-" + unsafeFileChars.ReplaceAllString(objects[0].Name(), "_")
+	}
+
+	if err := os.MkdirAll(client.SnapshotDir, 0700); err != nil {
+		return err
+	}
+
+	return snapshot.Save(filepath.Join(client.SnapshotDir, fileName+".json"))
+}
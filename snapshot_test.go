@@ -0,0 +1,166 @@
+package gonagios
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot_restoreUndoesChanges(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1", "contacts": "nagiosadmin"})
+	server.Add("host", map[string]string{"host_name": "host2", "address": "127.0.0.2", "contacts": "nagiosadmin"})
+
+	client := NewClient(server.URL, "token123")
+	client.SnapshotDir = filepath.Join(t.TempDir(), "snapshots")
+
+	assert.NoError(t, client.UpdateHost(&Host{HostName: "renamed", Address: "10.0.0.1"}, "host1"))
+	_, err := client.DeleteHost("host2")
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(client.SnapshotDir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Restore the snapshots in reverse order to get back to where we started
+	client.SnapshotDir = ""
+	for i := len(files) - 1; i >= 0; i-- {
+		snapshot, err := LoadSnapshot(files[i])
+		assert.NoError(t, err)
+		assert.NoError(t, client.Restore(snapshot))
+	}
+
+	assert.Nil(t, server.Find("host", "renamed"))
+	assert.Equal(t, "127.0.0.1", server.Find("host", "host1")["address"])
+	assert.Equal(t, "127.0.0.2", server.Find("host", "host2")["address"])
+	assert.Equal(t, 4, server.Applies())
+}
+
+func TestSnapshot_completeRestoreDeletesNewHosts(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+
+	client := NewClient(server.URL, "token123")
+
+	snapshot, err := client.Snapshot()
+	assert.NoError(t, err)
+	assert.True(t, snapshot.Complete)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	assert.NoError(t, snapshot.Save(path))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = client.NewHost(createNamedHostObject("host2"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Restore(snapshot))
+	assert.Len(t, server.Objects("host"), 1)
+	assert.NotNil(t, server.Find("host", "host1"))
+}
+
+func TestSnapshot_objectWrites(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("command", map[string]string{"command_name": "check_ping", "command_line": "check_ping -w 100"})
+	server.Add("service", map[string]string{"host_name": "host1", "service_description": "PING", "check_command": "check_ping"})
+
+	client := NewClient(server.URL, "token123")
+	client.SnapshotDir = filepath.Join(t.TempDir(), "snapshots")
+
+	session := client.NewConfigSession()
+	assert.NoError(t, session.NewObject(&ConfigObject{Type: ObjectService, Attributes: map[string]string{
+		"host_name": "host1", "service_description": "HTTP", "use": "generic-service", "check_command": "check_http",
+	}}))
+	assert.NoError(t, session.UpdateObject(&ConfigObject{Type: ObjectCommand, Attributes: map[string]string{
+		"command_name": "check_ping", "command_line": "check_ping -w 200",
+	}}))
+	assert.NoError(t, session.DeleteObject(&ConfigObject{Type: ObjectService, Attributes: map[string]string{
+		"host_name": "host1", "service_description": "PING",
+	}}))
+	assert.NoError(t, session.Commit())
+
+	files, err := filepath.Glob(filepath.Join(client.SnapshotDir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	client.SnapshotDir = ""
+	for i := len(files) - 1; i >= 0; i-- {
+		snapshot, err := LoadSnapshot(files[i])
+		assert.NoError(t, err)
+		assert.NoError(t, client.Restore(snapshot))
+	}
+
+	assert.Nil(t, server.Find("service", "host1", "HTTP"))
+	assert.Equal(t, "check_ping", server.Find("service", "host1", "PING")["check_command"])
+	assert.Equal(t, "check_ping -w 100", server.Find("command", "check_ping")["command_line"])
+}
+
+func TestSnapshot_renameRestoresReferences(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	addRenameFixtures(server)
+
+	client := NewClient(server.URL, "token123")
+	client.SnapshotDir = filepath.Join(t.TempDir(), "snapshots")
+
+	assert.NoError(t, client.RenameHost("host1", "renamed"))
+
+	// A rename takes a single snapshot, so it is undone in one step
+	files, err := filepath.Glob(filepath.Join(client.SnapshotDir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	snapshot, err := LoadSnapshot(files[0])
+	assert.NoError(t, err)
+
+	client.SnapshotDir = ""
+	assert.NoError(t, client.Restore(snapshot))
+
+	assert.Nil(t, server.Find("host", "renamed"))
+	assert.Equal(t, "127.0.0.1", server.Find("host", "host1")["address"])
+	assert.Equal(t, "host1", server.Find("host", "host2")["parents"])
+	assert.NotNil(t, server.Find("service", "host1", "PING"))
+	assert.Nil(t, server.Find("service", "renamed", "PING"))
+	assert.NotNil(t, server.Find("service", "host2,!host1", "HTTP"))
+	assert.Equal(t, "host1,host2", server.Find("hostgroup", "web")["members"])
+	assert.Equal(t, "host1", server.Find("hostdependency", "dep1")["dependent_host_name"])
+	assert.Equal(t, "host1", server.Find("hostescalation", "esc1")["host_name"])
+}
+
+func TestSnapshot_completeHoldsEveryType(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.Add("host", map[string]string{"host_name": "host1", "address": "127.0.0.1"})
+	server.Add("host", map[string]string{"name": "generic-host", "register": "0"})
+	server.Add("command", map[string]string{"command_name": "check_ping", "command_line": "check_ping"})
+	server.Unsupported("serviceescalation")
+
+	client := NewClient(server.URL, "token123")
+
+	snapshot, err := client.Snapshot()
+	assert.NoError(t, err)
+	assert.Len(t, snapshot.Objects, 2)
+
+	assert.NoError(t, client.NewConfigSession().NewObject(&ConfigObject{Type: ObjectCommand, Attributes: map[string]string{
+		"command_name": "check_http", "command_line": "check_http",
+	}}))
+
+	assert.NoError(t, client.Restore(snapshot))
+	assert.Nil(t, server.Find("command", "check_http"))
+	assert.NotNil(t, server.Find("command", "check_ping"))
+	// Templates are not in the snapshot, and are left alone
+	assert.Len(t, server.Objects("host"), 2)
+}
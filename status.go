@@ -0,0 +1,253 @@
+package gonagios
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// HostState is the current state of a host
+type HostState int
+
+// Host states, as Nagios reports them
+const (
+	HostUp          HostState = 0
+	HostDown        HostState = 1
+	HostUnreachable HostState = 2
+)
+
+// String returns the name Nagios uses for the state
+func (state HostState) String() string {
+	switch state {
+	case HostUp:
+		return "UP"
+	case HostDown:
+		return "DOWN"
+	case HostUnreachable:
+		return "UNREACHABLE"
+	}
+
+	return "UNKNOWN(" + strconv.Itoa(int(state)) + ")"
+}
+
+// ServiceState is the current state of a service
+type ServiceState int
+
+// Service states, as Nagios reports them
+const (
+	ServiceOK       ServiceState = 0
+	ServiceWarning  ServiceState = 1
+	ServiceCritical ServiceState = 2
+	ServiceUnknown  ServiceState = 3
+)
+
+// String returns the name Nagios uses for the state
+func (state ServiceState) String() string {
+	switch state {
+	case ServiceOK:
+		return "OK"
+	case ServiceWarning:
+		return "WARNING"
+	case ServiceCritical:
+		return "CRITICAL"
+	case ServiceUnknown:
+		return "UNKNOWN"
+	}
+
+	return "UNKNOWN(" + strconv.Itoa(int(state)) + ")"
+}
+
+// CheckStatus holds the status attributes shared by hosts and services
+type CheckStatus struct {
+	DisplayName            string    `json:"display_name,omitempty" nagios:"display_name"`
+	Output                 string    `json:"output" nagios:"output"`
+	LongOutput             string    `json:"long_output,omitempty" nagios:"long_output"`
+	PerfData               string    `json:"perfdata,omitempty" nagios:"perfdata"`
+	StateType              int       `json:"state_type" nagios:"state_type"`
+	CurrentCheckAttempt    int       `json:"current_check_attempt" nagios:"current_check_attempt"`
+	MaxCheckAttempts       int       `json:"max_check_attempts" nagios:"max_check_attempts"`
+	HasBeenChecked         bool      `json:"has_been_checked" nagios:"has_been_checked"`
+	ProblemAcknowledged    bool      `json:"problem_acknowledged" nagios:"problem_acknowledged"`
+	ScheduledDowntimeDepth int       `json:"scheduled_downtime_depth" nagios:"scheduled_downtime_depth"`
+	IsFlapping             bool      `json:"is_flapping" nagios:"is_flapping"`
+	NotificationsEnabled   bool      `json:"notifications_enabled" nagios:"notifications_enabled"`
+	LastCheck              time.Time `json:"last_check" nagios:"last_check"`
+	NextCheck              time.Time `json:"next_check" nagios:"next_check"`
+	LastStateChange        time.Time `json:"last_state_change" nagios:"last_state_change"`
+	StatusUpdateTime       time.Time `json:"status_update_time" nagios:"status_update_time"`
+}
+
+// HardState returns true when the state has been confirmed by every check attempt
+func (status *CheckStatus) HardState() bool {
+	return status.StateType == 1
+}
+
+// InDowntime returns true when the object is in scheduled downtime
+func (status *CheckStatus) InDowntime() bool {
+	return status.ScheduledDowntimeDepth > 0
+}
+
+// HostStatus is the current state of a host as reported by objects/hoststatus
+type HostStatus struct {
+	HostName     string    `json:"host_name" nagios:"host_name"`
+	Address      string    `json:"address,omitempty" nagios:"address"`
+	CurrentState HostState `json:"current_state" nagios:"current_state"`
+	CheckStatus
+}
+
+// Problem returns true when the host is not up
+func (status *HostStatus) Problem() bool {
+	return status.CurrentState != HostUp
+}
+
+// ServiceStatus is the current state of a service as reported by objects/servicestatus
+type ServiceStatus struct {
+	HostName           string       `json:"host_name" nagios:"host_name"`
+	ServiceDescription string       `json:"service_description" nagios:"service_description"`
+	CurrentState       ServiceState `json:"current_state" nagios:"current_state"`
+	CheckStatus
+}
+
+// Problem returns true when the service is not OK
+func (status *ServiceStatus) Problem() bool {
+	return status.CurrentState != ServiceOK
+}
+
+// ListHostStatus retrieves the status of every host
+func (client *Client) ListHostStatus() ([]HostStatus, error) {
+	var statuses []HostStatus
+
+	err := client.getRecords("hoststatus", "hoststatus", url.Values{}, func(attributes map[string]interface{}) error {
+		status := HostStatus{}
+		err := decodeValues(attributes, &status)
+		statuses = append(statuses, status)
+		return err
+	})
+
+	return statuses, err
+}
+
+// GetHostStatus retrieves the status of a single host
+// The error wraps ErrNotFound when XI has no status for the host
+func (client *Client) GetHostStatus(name string) (*HostStatus, error) {
+	var statuses []HostStatus
+
+	err := client.getRecords("hoststatus", "hoststatus", url.Values{"host_name": {name}}, func(attributes map[string]interface{}) error {
+		status := HostStatus{}
+		err := decodeValues(attributes, &status)
+		statuses = append(statuses, status)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range statuses {
+		if statuses[i].HostName == name {
+			return &statuses[i], nil
+		}
+	}
+
+	return nil, notFoundError("hoststatus", name)
+}
+
+// ListServiceStatus retrieves the status of the services of a host, or of every service when hostName is empty
+func (client *Client) ListServiceStatus(hostName string) ([]ServiceStatus, error) {
+	query := url.Values{}
+
+	if hostName != "" {
+		query.Set("host_name", hostName)
+	}
+
+	var statuses []ServiceStatus
+
+	err := client.getRecords("servicestatus", "servicestatus", query, func(attributes map[string]interface{}) error {
+		status := ServiceStatus{}
+		err := decodeValues(attributes, &status)
+		if hostName == "" || status.HostName == hostName {
+			statuses = append(statuses, status)
+		}
+		return err
+	})
+
+	return statuses, err
+}
+
+// getRecords retrieves the records of an objects endpoint and passes each of them to decode
+func (client *Client) getRecords(endpoint, recordType string, query url.Values, decode func(map[string]interface{}) error) error {
+	nagiosURL := addQueryParams(client.buildURL("objects", endpoint, http.MethodGet), query)
+
+	body, err := client.get("", nagiosURL)
+
+	if err != nil {
+		return err
+	}
+
+	records, err := objectRecords(body, recordType)
+
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := decode(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectRecords finds the records in a response from an objects endpoint
+// Depending on the XI version the records are a top level array, a list under the record type such as
+// {"recordcount": 2, "hoststatus": [...]}, or the same wrapped in a "hoststatuslist" object. A single
+// record is sent as an object instead of a list
+func objectRecords(body []byte, recordType string) ([]map[string]interface{}, error) {
+	var document interface{}
+
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+
+	if wrapper, ok := document.(map[string]interface{}); ok {
+		if list, ok := wrapper[recordType+"list"]; ok {
+			document = list
+		}
+	}
+
+	if wrapper, ok := document.(map[string]interface{}); ok {
+		records, ok := wrapper[recordType]
+
+		if !ok {
+			// An empty result only has a record count
+			if _, counted := wrapper["recordcount"]; counted {
+				return nil, nil
+			}
+
+			return nil, errors.New("unexpected " + recordType + " response")
+		}
+
+		document = records
+	}
+
+	switch records := document.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{records}, nil
+	case []interface{}:
+		list := make([]map[string]interface{}, 0, len(records))
+		for _, record := range records {
+			attributes, ok := record.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("unexpected " + recordType + " record")
+			}
+			list = append(list, attributes)
+		}
+		return list, nil
+	}
+
+	return nil, errors.New("unexpected " + recordType + " response")
+}
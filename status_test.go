@@ -0,0 +1,107 @@
+package gonagios
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus_listHostStatus(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.AddRecord("hoststatus", map[string]string{
+		"host_name":            "web01",
+		"current_state":        "1",
+		"state_type":           "1",
+		"output":               "CRITICAL - Host Unreachable",
+		"problem_acknowledged": "0",
+		"last_state_change":    "2019-10-01 10:00:00",
+		"last_check":           "0000-00-00 00:00:00",
+	})
+	server.AddRecord("hoststatus", map[string]string{"host_name": "web02", "current_state": "0"})
+
+	client := NewClient(server.URL, "token123")
+
+	statuses, err := client.ListHostStatus()
+
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, HostDown, statuses[0].CurrentState)
+	assert.Equal(t, "DOWN", statuses[0].CurrentState.String())
+	assert.True(t, statuses[0].Problem())
+	assert.True(t, statuses[0].HardState())
+	assert.Equal(t, "CRITICAL - Host Unreachable", statuses[0].Output)
+	assert.Equal(t, time.Date(2019, 10, 1, 10, 0, 0, 0, time.Local), statuses[0].LastStateChange)
+	assert.True(t, statuses[0].LastCheck.IsZero())
+	assert.False(t, statuses[1].Problem())
+
+	status, err := client.GetHostStatus("web02")
+	assert.NoError(t, err)
+	assert.Equal(t, "web02", status.HostName)
+
+	_, err = client.GetHostStatus("web03")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestStatus_listServiceStatus(t *testing.T) {
+	server := fakenagios.New()
+	defer server.Close()
+
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "HTTP", "current_state": "2"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web02", "service_description": "PING", "current_state": "0"})
+
+	client := NewClient(server.URL, "token123")
+
+	statuses, err := client.ListServiceStatus("")
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+
+	statuses, err = client.ListServiceStatus("web01")
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, ServiceCritical, statuses[0].CurrentState)
+	assert.Equal(t, "CRITICAL", statuses[0].CurrentState.String())
+
+	statuses, err = client.ListServiceStatus("web03")
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+}
+
+func TestStatus_responseShapes(t *testing.T) {
+	responses := []string{
+		`[{"host_name": "web01", "current_state": "1"}]`,
+		`{"recordcount": "1", "hoststatus": {"host_name": "web01", "current_state": 1}}`,
+		`{"hoststatuslist": {"recordcount": "1", "hoststatus": [{"host_name": "web01", "current_state": "1"}]}}`,
+	}
+
+	for _, response := range responses {
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/objects/hoststatus", r.URL.Path)
+			w.Write([]byte(response))
+		})
+
+		statuses, err := client.ListHostStatus()
+
+		assert.NoError(t, err, response)
+		assert.Equal(t, []HostStatus{{HostName: "web01", CurrentState: HostDown}}, statuses, response)
+
+		server.Close()
+	}
+}
+
+func TestStatus_emptyResponse(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"hoststatuslist": {"recordcount": "0"}}`))
+	})
+	defer server.Close()
+
+	statuses, err := client.ListHostStatus()
+
+	assert.NoError(t, err)
+	assert.Empty(t, statuses)
+}
@@ -0,0 +1,324 @@
+package gonagios
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIntervalLength is the length of one interval unit when interval_length is not changed in nagios.cfg
+const DefaultIntervalLength = 60 * time.Second
+
+// TypedHost is a Nagios host with typed attributes, as an alternative to the all-string Host
+// Intervals are durations converted to and from Nagios interval units using IntervalLength, while
+// freshness_threshold is always in seconds. Option letters are parsed into typed option sets.
+// A zero value or nil pointer means the attribute is not set, so it is inherited from the templates.
+// Durations are pointers since zero is meaningful, such as a notification_interval of 0 to notify only once
+// The nagios tags name the attribute each field is sent as, see encodeValues. It marshals to JSON with
+// native numbers and booleans and durations such as "10m0s", and unmarshals from either that form or the
+// all-string form returned by Nagios XI
+type TypedHost struct {
+	HostName                   string                  `nagios:"host_name"`
+	Address                    string                  `nagios:"address"`
+	DisplayName                string                  `nagios:"display_name"`
+	MaxCheckAttempts           int                     `nagios:"max_check_attempts"`
+	CheckPeriod                string                  `nagios:"check_period"`
+	NotificationInterval       *time.Duration          `nagios:"notification_interval,interval"`
+	NotificationPeriod         string                  `nagios:"notification_period"`
+	Contacts                   []string                `nagios:"contacts"`
+	Alias                      string                  `nagios:"alias"`
+	Templates                  []string                `nagios:"use"`
+	CheckCommand               string                  `nagios:"check_command"`
+	ContactGroups              []string                `nagios:"contact_groups"`
+	Notes                      string                  `nagios:"notes"`
+	NotesURL                   string                  `nagios:"notes_url"`
+	ActionURL                  string                  `nagios:"action_url"`
+	InitialState               string                  `nagios:"initial_state"`
+	RetryInterval              *time.Duration          `nagios:"retry_interval,interval"`
+	PassiveChecksEnabled       *bool                   `nagios:"passive_checks_enabled"`
+	ActiveChecksEnabled        *bool                   `nagios:"active_checks_enabled"`
+	ObsessOverHost             *bool                   `nagios:"obsess_over_host"`
+	EventHandler               string                  `nagios:"event_handler"`
+	EventHandlerEnabled        *bool                   `nagios:"event_handler_enabled"`
+	FlapDetectionEnabled       *bool                   `nagios:"flap_detection_enabled"`
+	FlapDetectionOptions       HostFlapOptions         `nagios:"flap_detection_options"`
+	LowFlapThreshold           *float64                `nagios:"low_flap_threshold"`
+	HighFlapThreshold          *float64                `nagios:"high_flap_threshold"`
+	ProcessPerfData            *bool                   `nagios:"process_perf_data"`
+	RetainStatusInformation    *bool                   `nagios:"retain_status_information"`
+	RetainNonstatusInformation *bool                   `nagios:"retain_nonstatus_information"`
+	CheckFreshness             *bool                   `nagios:"check_freshness"`
+	FreshnessThreshold         *time.Duration          `nagios:"freshness_threshold"`
+	FirstNotificationDelay     *time.Duration          `nagios:"first_notification_delay,interval"`
+	NotificationOptions        HostNotificationOptions `nagios:"notification_options"`
+	NotificationsEnabled       *bool                   `nagios:"notifications_enabled"`
+	StalkingOptions            HostStalkingOptions     `nagios:"stalking_options"`
+	IconImage                  string                  `nagios:"icon_image"`
+	IconImageAlt               string                  `nagios:"icon_image_alt"`
+	VRMLImage                  string                  `nagios:"vrml_image"`
+	StatusMapImage             string                  `nagios:"statusmap_image"`
+	TwoDCoords                 string                  `nagios:"2d_coords"`
+	ThreeDCoords               string                  `nagios:"3d_coords"`
+	Register                   *bool                   `nagios:"register"`
+	FreeVariables              map[string]string       `nagios:",vars"`
+	// IntervalLength is the length of one Nagios interval unit. Zero means DefaultIntervalLength
+	IntervalLength time.Duration
+}
+
+// Bool returns a pointer to the value, for setting the optional boolean attributes of a TypedHost
+func Bool(value bool) *bool {
+	return &value
+}
+
+// Float returns a pointer to the value, for setting the optional numeric attributes of a TypedHost
+func Float(value float64) *float64 {
+	return &value
+}
+
+// Duration returns a pointer to the value, for setting the intervals of a TypedHost
+func Duration(value time.Duration) *time.Duration {
+	return &value
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	freeVarsKey         = "free_variables"
+)
+
+// Typed converts the host to a TypedHost, using intervalLength to convert intervals to durations
+// Pass zero to use DefaultIntervalLength
+func (host *Host) Typed(intervalLength time.Duration) (*TypedHost, error) {
+	typed := &TypedHost{
+		IntervalLength: intervalLength,
+	}
+
+	values, err := encodeValues(host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeValues(valuesAttributes(values), typed); err != nil {
+		return nil, err
+	}
+
+	return typed, nil
+}
+
+// Host converts the typed host back to the all-string Host used by the client methods
+func (typed *TypedHost) Host() *Host {
+	host := &Host{}
+
+	// Every field of both structs has a type the encoder supports, so neither conversion can fail
+	values, _ := encodeValues(typed)
+	decodeValues(valuesAttributes(values), host)
+
+	return host
+}
+
+// MarshalJSON encodes the host with native JSON numbers, booleans and lists, leaving out unset attributes
+func (typed TypedHost) MarshalJSON() ([]byte, error) {
+	output := map[string]interface{}{}
+
+	typedValue := reflect.ValueOf(typed)
+	typedType := typedValue.Type()
+
+	for i := 0; i < typedType.NumField(); i++ {
+		name, options, tagged := parseNagiosTag(typedType.Field(i))
+		field := typedValue.Field(i)
+
+		if !tagged || name == "-" || isUnset(field) {
+			continue
+		}
+
+		switch {
+		case options["vars"]:
+			output[freeVarsKey] = field.Interface()
+		case field.Kind() == reflect.Ptr && field.Type().Elem() == durationType:
+			output[name] = field.Elem().Interface().(time.Duration).String()
+		case field.Kind() == reflect.Ptr:
+			output[name] = field.Elem().Interface()
+		default:
+			output[name] = field.Interface()
+		}
+	}
+
+	return json.Marshal(output)
+}
+
+// UnmarshalJSON decodes a host from native JSON values or from the strings Nagios XI returns
+// Durations may be a number of interval units (seconds for freshness_threshold) or a string such as "10m"
+func (typed *TypedHost) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	attributes := map[string]interface{}{}
+
+	for key, value := range raw {
+		if variables, ok := value.(map[string]interface{}); ok && key == freeVarsKey {
+			for name, variable := range variables {
+				attributes[name] = variable
+			}
+			continue
+		}
+
+		attributes[key] = value
+	}
+
+	*typed = TypedHost{
+		IntervalLength: typed.IntervalLength,
+	}
+
+	return decodeValues(attributes, typed)
+}
+
+// intervalLength returns the length of one interval unit, see intervalUnit
+func (typed *TypedHost) intervalLength() time.Duration {
+	if typed.IntervalLength <= 0 {
+		return DefaultIntervalLength
+	}
+
+	return typed.IntervalLength
+}
+
+// valuesAttributes converts encoded URL parameters to the attributes decodeValues reads
+func valuesAttributes(values url.Values) map[string]interface{} {
+	attributes := make(map[string]interface{}, len(values))
+	for key := range values {
+		attributes[key] = values.Get(key)
+	}
+
+	return attributes
+}
+
+// isUnset returns true for the zero value of a field, which means the attribute is not set
+func isUnset(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return field.IsNil() || (field.Kind() != reflect.Ptr && field.Len() == 0)
+	}
+
+	return field.IsZero()
+}
+
+// splitList splits a comma separated Nagios list, dropping empty items
+func splitList(value string) []string {
+	var list []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// parseInt parses an integer, accepting the "5.0" form XI sometimes returns
+func parseInt(value string) (int, error) {
+	value = strings.TrimSpace(value)
+
+	if number, err := strconv.Atoi(value); err == nil {
+		return number, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+
+	if err != nil || number != math.Trunc(number) {
+		return 0, errors.New("'" + value + "' is not an integer")
+	}
+
+	return int(number), nil
+}
+
+// parseBool parses the 1/0 Nagios uses for booleans, as well as true/false
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true":
+		return true, nil
+	case "0", "false":
+		return false, nil
+	}
+
+	return false, errors.New("'" + value + "' is not a boolean")
+}
+
+// parseDuration parses a number of units, or a duration string such as "10m"
+func parseDuration(value string, unit time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(number * float64(unit)), nil
+	}
+
+	duration, err := time.ParseDuration(value)
+
+	if err != nil {
+		return 0, errors.New("'" + value + "' is not a number of intervals or a duration")
+	}
+
+	return duration, nil
+}
+
+// parseTime parses a timestamp in the format XI uses, a Unix timestamp or RFC 3339
+// The empty value and the zero timestamps XI sends for events that never happened give the zero time
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	switch value {
+	case "", "0", "0000-00-00 00:00:00":
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	if timestamp, err := time.ParseInLocation(programTimeLayout, value, time.Local); err == nil {
+		return timestamp, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, errors.New("'" + value + "' is not a timestamp")
+	}
+
+	return timestamp, nil
+}
+
+// stringifyJSON converts a decoded JSON value to the string form Nagios uses
+func stringifyJSON(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		return convertBoolToIntToString(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		var items []string
+		for _, item := range value {
+			items = append(items, stringifyJSON(item))
+		}
+		return strings.Join(items, ",")
+	}
+
+	encoded, _ := json.Marshal(value)
+
+	return string(encoded)
+}
@@ -0,0 +1,112 @@
+package gonagios
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedHost_unmarshalXIResponse(t *testing.T) {
+	body := `{"host_name":"host1","address":"127.0.0.2","max_check_attempts":"5","notification_interval":10,
+		"retry_interval":"0.5","freshness_threshold":"90","active_checks_enabled":"0","check_freshness":1,
+		"low_flap_threshold":"5.5","contacts":["nagiosadmin","admin2"],"use":"generic-host","_SNMP_COMMUNITY":"public"}`
+
+	typed := &TypedHost{}
+
+	assert.NoError(t, json.Unmarshal([]byte(body), typed))
+	assert.Equal(t, 5, typed.MaxCheckAttempts)
+	assert.Equal(t, 10*time.Minute, *typed.NotificationInterval)
+	assert.Equal(t, 30*time.Second, *typed.RetryInterval)
+	assert.Equal(t, 90*time.Second, *typed.FreshnessThreshold)
+	assert.Equal(t, false, *typed.ActiveChecksEnabled)
+	assert.Equal(t, true, *typed.CheckFreshness)
+	assert.Equal(t, 5.5, *typed.LowFlapThreshold)
+	assert.Nil(t, typed.PassiveChecksEnabled)
+	assert.Equal(t, []string{"nagiosadmin", "admin2"}, typed.Contacts)
+	assert.Equal(t, []string{"generic-host"}, typed.Templates)
+	assert.Equal(t, map[string]string{"_SNMP_COMMUNITY": "public"}, typed.FreeVariables)
+}
+
+func TestTypedHost_intervalLength(t *testing.T) {
+	typed := &TypedHost{IntervalLength: time.Second}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"notification_interval":"600"}`), typed))
+	assert.Equal(t, 10*time.Minute, *typed.NotificationInterval)
+	assert.Equal(t, "600", typed.Host().NotificationInterval)
+}
+
+func TestTypedHost_invalidValues(t *testing.T) {
+	typed := &TypedHost{}
+
+	err := json.Unmarshal([]byte(`{"max_check_attempts":"five","active_checks_enabled":"maybe"}`), typed)
+
+	assert.EqualError(t, err, "invalid attributes: active_checks_enabled: 'maybe' is not a boolean; max_check_attempts: 'five' is not an integer")
+}
+
+func TestTypedHost_roundTrip(t *testing.T) {
+	typed := &TypedHost{
+		HostName:             "host1",
+		Address:              "127.0.0.2",
+		MaxCheckAttempts:     5,
+		CheckPeriod:          "24x7",
+		NotificationInterval: Duration(10 * time.Minute),
+		NotificationPeriod:   "24x7",
+		Contacts:             []string{"nagiosadmin"},
+		Templates:            []string{"generic-host"},
+		ActiveChecksEnabled:  Bool(false),
+		HighFlapThreshold:    Float(30),
+	}
+
+	host := typed.Host()
+
+	assert.Equal(t, "5", host.MaxCheckAttempts)
+	assert.Equal(t, "10", host.NotificationInterval)
+	assert.Equal(t, "0", host.ActiveChecksEnabled)
+	assert.Equal(t, []interface{}{"nagiosadmin"}, host.Contacts)
+
+	converted, err := host.Typed(0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, typed, converted)
+
+	body, err := json.Marshal(typed)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"host_name":"host1","address":"127.0.0.2","max_check_attempts":5,"check_period":"24x7",
+		"notification_interval":"10m0s","notification_period":"24x7","contacts":["nagiosadmin"],"use":["generic-host"],
+		"active_checks_enabled":false,"high_flap_threshold":30}`, string(body))
+
+	decoded := &TypedHost{}
+
+	assert.NoError(t, json.Unmarshal(body, decoded))
+	assert.Equal(t, typed, decoded)
+}
+
+func TestTypedHost_zeroDurations(t *testing.T) {
+	typed := &TypedHost{
+		HostName:               "host1",
+		NotificationInterval:   Duration(0),
+		FirstNotificationDelay: Duration(0),
+	}
+
+	host := typed.Host()
+
+	// Zero is sent, it means notify only once and notify straight away
+	assert.Equal(t, "0", host.NotificationInterval)
+	assert.Equal(t, "0", host.FirstNotificationDelay)
+	assert.Equal(t, "", host.RetryInterval)
+
+	body, err := json.Marshal(typed)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"first_notification_delay":"0s"`)
+	assert.NotContains(t, string(body), "retry_interval")
+
+	decoded := &TypedHost{}
+
+	assert.NoError(t, json.Unmarshal(body, decoded))
+	assert.Equal(t, time.Duration(0), *decoded.FirstNotificationDelay)
+	assert.Nil(t, decoded.RetryInterval)
+}
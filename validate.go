@@ -0,0 +1,298 @@
+package gonagios
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// illegalObjectNameChars are the characters Nagios does not allow in object names by default
+// See illegal_object_name_chars in nagios.cfg
+const illegalObjectNameChars = "`~!$%^&*|'\"<>?,()="
+
+// FieldError describes a single problem with an attribute of an object
+type FieldError struct {
+	Field   string
+	Problem string
+}
+
+// ValidationError lists every problem found in an object before it is sent to Nagios
+type ValidationError struct {
+	ObjectType string
+	Name       string
+	Problems   []FieldError
+}
+
+// Error lists every problem found in the object
+func (validationError *ValidationError) Error() string {
+	var message strings.Builder
+
+	message.WriteString(validationError.ObjectType + " '" + validationError.Name + "' is invalid: ")
+
+	for i, problem := range validationError.Problems {
+		if i > 0 {
+			message.WriteString("; ")
+		}
+		message.WriteString(problem.Field + ": " + problem.Problem)
+	}
+
+	return message.String()
+}
+
+// Unwrap returns ErrValidation so client-side and server-side validation failures can be handled the same way
+func (validationError *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// validator collects the problems found in an object
+type validator struct {
+	problems []FieldError
+}
+
+// Validate checks that the host can be created in Nagios, without sending anything
+// Every problem is returned at once in a *ValidationError
+func (host *Host) Validate() error {
+	return validateHost(host, true)
+}
+
+// Validate checks that the host can be created in Nagios, without sending anything
+func (typed *TypedHost) Validate() error {
+	return typed.Host().Validate()
+}
+
+// optionKinds holds the option set of every attribute made of option letters, by object type
+var optionKinds = map[ObjectType]map[string]optionKind{
+	ObjectHost: {
+		"notification_options":   hostNotificationKind,
+		"flap_detection_options": hostFlapKind,
+		"stalking_options":       hostStalkingKind,
+	},
+	ObjectService: {
+		"notification_options":   serviceNotificationKind,
+		"flap_detection_options": serviceFlapKind,
+		"stalking_options":       serviceStalkingKind,
+	},
+	ObjectContact: {
+		"host_notification_options":    hostNotificationKind,
+		"service_notification_options": serviceNotificationKind,
+	},
+}
+
+// Validate checks the option letters of the object, such as the notification_options of a service,
+// against the options its type allows. Every problem is returned at once in a *ValidationError
+func (object *ConfigObject) Validate() error {
+	check := &validator{}
+
+	for attribute, kind := range optionKinds[object.Type] {
+		kind := kind
+		check.options(attribute, object.Attributes[attribute], func(value string) error {
+			_, err := kind.parse(value)
+			return err
+		})
+	}
+
+	return check.result(string(object.Type), object.Name())
+}
+
+// validateHost checks the attributes of a host
+// Required attributes are only checked when creating a host, since an update only sends what changes
+func validateHost(host *Host, create bool) error {
+	check := &validator{}
+
+	if create {
+		check.required("host_name", host.HostName)
+		check.required("address", host.Address)
+		check.required("max_check_attempts", host.MaxCheckAttempts)
+		check.required("check_period", host.CheckPeriod)
+		check.required("notification_interval", host.NotificationInterval)
+		check.required("notification_period", host.NotificationPeriod)
+
+		if len(host.Contacts) == 0 && len(host.ContactGroups) == 0 {
+			check.add("contacts", "contacts or contact_groups is required")
+		}
+	}
+
+	check.objectName("host_name", host.HostName)
+	check.objectName("check_period", host.CheckPeriod)
+	check.objectName("notification_period", host.NotificationPeriod)
+	check.objectNames("contacts", host.Contacts)
+	check.objectNames("contact_groups", host.ContactGroups)
+	check.objectNames("use", host.Templates)
+
+	check.integer("max_check_attempts", host.MaxCheckAttempts, 1)
+	check.integer("freshness_threshold", host.FreshnessThreshold, 0)
+	check.number("notification_interval", host.NotificationInterval, 0, -1)
+	check.number("retry_interval", host.RetryInterval, 0, -1)
+	check.number("first_notification_delay", host.FirstNotificationDelay, 0, -1)
+	check.number("low_flap_threshold", host.LowFlapThreshold, 0, 100)
+	check.number("high_flap_threshold", host.HighFlapThreshold, 0, 100)
+
+	if host.LowFlapThreshold != "" && host.HighFlapThreshold != "" {
+		low, lowErr := strconv.ParseFloat(host.LowFlapThreshold, 64)
+		high, highErr := strconv.ParseFloat(host.HighFlapThreshold, 64)
+		if lowErr == nil && highErr == nil && low > high {
+			check.add("low_flap_threshold", "must not be greater than high_flap_threshold")
+		}
+	}
+
+	check.boolean("passive_checks_enabled", host.PassiveChecksEnabled)
+	check.boolean("active_checks_enabled", host.ActiveChecksEnabled)
+	check.boolean("obsess_over_host", host.ObsessOverHost)
+	check.boolean("event_handler_enabled", host.EventHandlerEnabled)
+	check.boolean("flap_detection_enabled", host.FlapDetectionEnabled)
+	check.boolean("process_perf_data", host.ProcessPerfData)
+	check.boolean("retain_status_information", host.RetainStatusInformation)
+	check.boolean("retain_nonstatus_information", host.RetainNonstatusInformation)
+	check.boolean("check_freshness", host.CheckFreshness)
+	check.boolean("notifications_enabled", host.NotificationsEnabled)
+	check.boolean("register", host.Register)
+
+	check.initialState("initial_state", host.InitialState, "odu")
+	check.options("notification_options", host.NotificationOptions, func(value string) error {
+		_, err := ParseHostNotificationOptions(value)
+		return err
+	})
+	check.options("flap_detection_options", stringifyJSON(host.FlapDetectionOptions), func(value string) error {
+		_, err := ParseHostFlapOptions(value)
+		return err
+	})
+	check.options("stalking_options", host.StalkingOptions, func(value string) error {
+		_, err := ParseHostStalkingOptions(value)
+		return err
+	})
+
+	check.variables("free_variables", host.FreeVariables)
+
+	return check.result(objectType, host.HostName)
+}
+
+// add records a problem with a field
+func (check *validator) add(field, problem string) {
+	check.problems = append(check.problems, FieldError{Field: field, Problem: problem})
+}
+
+// result returns a *ValidationError if any problem was found
+func (check *validator) result(objectType, name string) error {
+	if len(check.problems) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(check.problems, func(i, j int) bool {
+		return check.problems[i].Field < check.problems[j].Field
+	})
+
+	return &ValidationError{
+		ObjectType: objectType,
+		Name:       name,
+		Problems:   check.problems,
+	}
+}
+
+// required checks that a field is set
+func (check *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		check.add(field, "is required")
+	}
+}
+
+// objectName checks that a name does not contain characters Nagios does not allow in object names
+func (check *validator) objectName(field, value string) {
+	if value == "" {
+		return
+	}
+
+	if strings.TrimSpace(value) != value {
+		check.add(field, "'"+value+"' must not start or end with whitespace")
+	}
+
+	if index := strings.IndexAny(value, illegalObjectNameChars); index >= 0 {
+		check.add(field, "'"+value+"' contains illegal character '"+string(value[index])+"'")
+	}
+}
+
+// commandArgument checks that a value can be passed in an external command, where ';' separates the
+// arguments and a newline ends the command
+func (check *validator) commandArgument(field, value string) {
+	if strings.ContainsAny(value, ";\r\n") {
+		check.add(field, "must not contain ';' or line breaks")
+	}
+}
+
+// objectNames checks every name in a list of object names
+func (check *validator) objectNames(field string, values []interface{}) {
+	for _, value := range values {
+		name, ok := value.(string)
+
+		if !ok {
+			check.add(field, "must be a list of strings")
+			return
+		}
+
+		check.objectName(field, name)
+	}
+}
+
+// integer checks that a field is an integer no smaller than min
+func (check *validator) integer(field, value string, min int) {
+	if value == "" {
+		return
+	}
+
+	number, err := strconv.Atoi(value)
+
+	if err != nil {
+		check.add(field, "'"+value+"' is not an integer")
+		return
+	}
+
+	if number < min {
+		check.add(field, "must be at least "+strconv.Itoa(min))
+	}
+}
+
+// number checks that a field is a number between min and max. A negative max means there is no upper bound
+func (check *validator) number(field, value string, min, max float64) {
+	if value == "" {
+		return
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		check.add(field, "'"+value+"' is not a number")
+		return
+	}
+
+	if number < min || (max >= 0 && number > max) {
+		if max >= 0 {
+			check.add(field, "must be between "+strconv.FormatFloat(min, 'f', -1, 64)+" and "+strconv.FormatFloat(max, 'f', -1, 64))
+		} else {
+			check.add(field, "must be at least "+strconv.FormatFloat(min, 'f', -1, 64))
+		}
+	}
+}
+
+// boolean checks that a field is one of the 0 or 1 Nagios uses for booleans
+func (check *validator) boolean(field, value string) {
+	if value != "" && value != "0" && value != "1" {
+		check.add(field, "'"+value+"' must be 0 or 1")
+	}
+}
+
+// initialState checks that a field is one of the allowed state letters
+func (check *validator) initialState(field, value, allowed string) {
+	if value != "" && (len(value) != 1 || !strings.Contains(allowed, value)) {
+		check.add(field, "'"+value+"' is not one of "+strings.Join(strings.Split(allowed, ""), ","))
+	}
+}
+
+// options checks a list of option letters with the parser of the matching option set
+func (check *validator) options(field, value string, parse func(value string) error) {
+	if value == "" {
+		return
+	}
+
+	if err := parse(value); err != nil {
+		check.add(field, err.Error())
+	}
+}
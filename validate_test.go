@@ -0,0 +1,65 @@
+package gonagios
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_validHost(t *testing.T) {
+	assert.NoError(t, createHostObject().Validate())
+}
+
+func TestValidate_reportsEveryProblem(t *testing.T) {
+	host := &Host{
+		HostName:             "web(1)",
+		Address:              "127.0.0.1",
+		MaxCheckAttempts:     "0",
+		CheckPeriod:          "24x7",
+		NotificationInterval: "-5",
+		NotificationOptions:  "d,w",
+		FlapDetectionOptions: []interface{}{"o", "n"},
+		LowFlapThreshold:     "50",
+		HighFlapThreshold:    "20",
+		ActiveChecksEnabled:  "yes",
+	}
+
+	err := host.Validate()
+
+	var validationError *ValidationError
+	assert.True(t, errors.As(err, &validationError))
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.Equal(t, []FieldError{
+		{Field: "active_checks_enabled", Problem: "'yes' must be 0 or 1"},
+		{Field: "contacts", Problem: "contacts or contact_groups is required"},
+		{Field: "flap_detection_options", Problem: "'n' cannot be combined with other host flap detection options"},
+		{Field: "host_name", Problem: "'web(1)' contains illegal character '('"},
+		{Field: "low_flap_threshold", Problem: "must not be greater than high_flap_threshold"},
+		{Field: "max_check_attempts", Problem: "must be at least 1"},
+		{Field: "notification_interval", Problem: "must be at least 0"},
+		{Field: "notification_options", Problem: "'w' is a service option, not a host notification option"},
+		{Field: "notification_period", Problem: "is required"},
+	}, validationError.Problems)
+}
+
+func TestValidate_beforeRoundTrip(t *testing.T) {
+	var calls int32
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+	defer server.Close()
+
+	_, err := client.NewHost(&Host{HostName: "host1"})
+
+	assert.True(t, errors.Is(err, ErrValidation))
+
+	// Updates only send what changes, so required attributes are not checked
+	err = client.UpdateHost(&Host{MaxCheckAttempts: "many"}, "host1")
+
+	assert.EqualError(t, err, "host '' is invalid: max_check_attempts: 'many' is not an integer")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,202 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ANSI escape sequences used by Render
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorPurple = "\x1b[35m"
+)
+
+// RenderOptions control how Render draws the view
+type RenderOptions struct {
+	// Clear clears the terminal before drawing, so each refresh replaces the last one
+	Clear bool
+	// Color uses ANSI colors for states and highlights
+	Color bool
+	// Width cuts lines to this many characters. Zero means lines are never cut
+	Width int
+	// Err is shown below the header when the last poll failed, while the last snapshot is still shown
+	Err error
+}
+
+// Render draws the problems of a snapshot, marking new problems with + and listing the problems that
+// recovered since the previous refresh
+func Render(w io.Writer, snapshot *Snapshot, changes *Changes, options RenderOptions) error {
+	var view strings.Builder
+
+	if options.Clear {
+		view.WriteString(clearScreen)
+	}
+
+	header := fmt.Sprintf("%d problems, %d new, %d recovered - updated %s", len(snapshot.Problems), len(changes.New), len(changes.Recovered), snapshot.Time.Format("15:04:05"))
+	view.WriteString(paint(options, colorBold, cut(header, options.Width)) + "\n")
+
+	if options.Err != nil {
+		view.WriteString(paint(options, colorRed, cut("poll failed: "+describeError(options.Err), options.Width)) + "\n")
+	}
+
+	view.WriteString("\n")
+
+	// Lines are cut and colored after the columns are lined up, so escape sequences do not count as width
+	var rows strings.Builder
+	table := tabwriter.NewWriter(&rows, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, " \tSTATE\tHOST\tSERVICE\tDURATION\tOUTPUT")
+
+	for i := range snapshot.Problems {
+		problem := &snapshot.Problems[i]
+
+		marker := " "
+		if changes.New[problem.Key()] {
+			marker = "+"
+		}
+
+		state := problem.State
+		if problem.Acknowledged {
+			state += " (ack)"
+		} else if problem.InDowntime {
+			state += " (downtime)"
+		}
+
+		fmt.Fprintln(table, strings.Join([]string{marker, state, problem.HostName, orDash(problem.ServiceDescription), Age(problem.Since, snapshot.Time), singleLine(problem.Output)}, "\t"))
+	}
+
+	table.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(rows.String(), "\n"), "\n")
+
+	for i, line := range lines {
+		line = cut(line, options.Width)
+
+		if i == 0 {
+			view.WriteString(paint(options, colorBold, line) + "\n")
+			continue
+		}
+
+		problem := &snapshot.Problems[i-1]
+
+		switch {
+		case changes.New[problem.Key()]:
+			line = paint(options, colorBold+stateColor(problem.Severity), line)
+		case problem.Handled():
+			line = paint(options, colorDim, line)
+		default:
+			line = paint(options, stateColor(problem.Severity), line)
+		}
+
+		view.WriteString(line + "\n")
+	}
+
+	if len(changes.Recovered) > 0 {
+		view.WriteString("\n" + paint(options, colorBold, "RECOVERED") + "\n")
+
+		for i := range changes.Recovered {
+			problem := &changes.Recovered[i]
+			view.WriteString(paint(options, colorGreen, cut("- "+problem.Key()+" was "+problem.State, options.Width)) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, view.String())
+
+	return err
+}
+
+// stateColor returns the color of a severity
+func stateColor(severity Severity) string {
+	switch severity {
+	case SeverityDown, SeverityUnreachable, SeverityCritical:
+		return colorRed
+	case SeverityWarning:
+		return colorYellow
+	}
+
+	return colorPurple
+}
+
+// paint wraps text in an ANSI color when colors are on
+func paint(options RenderOptions, color, text string) string {
+	if !options.Color {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// cut shortens a line to width characters
+func cut(line string, width int) string {
+	runes := []rune(line)
+
+	if width <= 0 || len(runes) <= width {
+		return line
+	}
+
+	return string(runes[:width])
+}
+
+// singleLine keeps the first line of plugin output
+func singleLine(output string) string {
+	if index := strings.IndexAny(output, "\r\n"); index >= 0 {
+		output = output[:index]
+	}
+
+	return strings.Replace(output, "\t", " ", -1)
+}
+
+// describeError returns the message of a poll error. Only the reason of a transport error is shown, without
+// the request URL it quotes
+func describeError(err error) string {
+	var urlError *url.Error
+
+	if errors.As(err, &urlError) {
+		return urlError.Err.Error()
+	}
+
+	return err.Error()
+}
+
+// orDash returns - for an empty cell
+func orDash(text string) string {
+	if text == "" {
+		return "-"
+	}
+
+	return text
+}
+
+// Age formats how long something has lasted, such as 45s, 3h12m or 2d4h, or - when since is the zero time
+func Age(since, now time.Time) string {
+	if since.IsZero() {
+		return "-"
+	}
+
+	duration := now.Sub(since)
+	if duration < 0 {
+		duration = 0
+	}
+
+	switch {
+	case duration < time.Minute:
+		return duration.Truncate(time.Second).String()
+	case duration < 24*time.Hour:
+		return strings.TrimSuffix(duration.Truncate(time.Minute).String(), "0s")
+	}
+
+	days := int(duration / (24 * time.Hour))
+
+	return fmt.Sprintf("%dd%dh", days, int(duration%(24*time.Hour)/time.Hour))
+}
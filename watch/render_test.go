@@ -0,0 +1,84 @@
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender_plain(t *testing.T) {
+	now := time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	snapshot := &Snapshot{Time: now, Problems: []Problem{
+		{HostName: "db01", State: "DOWN", Severity: SeverityDown, Since: now.Add(-26 * time.Hour), Output: "PING CRITICAL\nsecond line"},
+		{HostName: "web01", ServiceDescription: "HTTP", State: "CRITICAL", Severity: SeverityCritical, Since: now.Add(-90 * time.Minute), Output: "refused"},
+		{HostName: "web01", ServiceDescription: "Load", State: "WARNING", Severity: SeverityWarning, Since: now.Add(-30 * time.Second), Acknowledged: true},
+	}}
+	changes := &Changes{
+		New:       map[string]bool{"web01/HTTP": true},
+		Recovered: []Problem{{HostName: "web02", ServiceDescription: "Disk", State: "CRITICAL"}},
+	}
+
+	var output bytes.Buffer
+
+	assert.NoError(t, Render(&output, snapshot, changes, RenderOptions{}))
+	assert.Equal(t, strings.Join([]string{
+		"3 problems, 1 new, 1 recovered - updated 12:00:00",
+		"",
+		"   STATE          HOST   SERVICE  DURATION  OUTPUT",
+		"   DOWN           db01   -        1d2h      PING CRITICAL",
+		"+  CRITICAL       web01  HTTP     1h30m     refused",
+		"   WARNING (ack)  web01  Load     30s       ",
+		"",
+		"RECOVERED",
+		"- web02/Disk was CRITICAL",
+		"",
+	}, "\n"), output.String())
+}
+
+func TestRender_options(t *testing.T) {
+	now := time.Now()
+
+	snapshot := &Snapshot{Time: now, Problems: []Problem{
+		{HostName: "web01", ServiceDescription: "HTTP", State: "CRITICAL", Severity: SeverityCritical, Output: strings.Repeat("x", 100)},
+	}}
+
+	var output bytes.Buffer
+
+	err := Render(&output, snapshot, &Changes{New: map[string]bool{"web01/HTTP": true}}, RenderOptions{Clear: true, Color: true, Width: 40, Err: errors.New("timeout")})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(output.String(), clearScreen))
+	assert.Contains(t, output.String(), colorRed+"poll failed: timeout"+colorReset)
+	assert.Contains(t, output.String(), colorBold+colorRed+"+")
+
+	for _, line := range strings.Split(output.String(), "\n") {
+		line = strings.NewReplacer(clearScreen, "", colorReset, "", colorBold, "", colorRed, "").Replace(line)
+		assert.True(t, len(line) <= 40, line)
+	}
+}
+
+func TestRender_transportError(t *testing.T) {
+	var output bytes.Buffer
+
+	err := &url.Error{Op: "Get", URL: "https://nagios.local/nagiosxi/api/v1/objects/hoststatus?apikey=token123", Err: errors.New("connection refused")}
+
+	assert.NoError(t, Render(&output, &Snapshot{}, &Changes{}, RenderOptions{Err: err}))
+	assert.Contains(t, output.String(), "poll failed: connection refused\n")
+	assert.NotContains(t, output.String(), "token123")
+}
+
+func TestRender_age(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, "-", Age(time.Time{}, now))
+	assert.Equal(t, "0s", Age(now.Add(time.Minute), now))
+	assert.Equal(t, "59s", Age(now.Add(-59*time.Second), now))
+	assert.Equal(t, "5m", Age(now.Add(-5*time.Minute-10*time.Second), now))
+	assert.Equal(t, "2d0h", Age(now.Add(-48*time.Hour), now))
+}
@@ -0,0 +1,192 @@
+// Package watch polls the state of hosts and services and shows the current problems in a terminal,
+// highlighting the problems that appeared and the ones that recovered since the last refresh
+//
+//	watcher := &watch.Watcher{Client: client, Interval: 30 * time.Second, Output: os.Stdout}
+//	err := watcher.Run(ctx)
+package watch
+
+import (
+	"sort"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// Severity ranks problems, the most severe first
+type Severity int
+
+// Severities, from least to most severe
+const (
+	SeverityUnknown     Severity = 1
+	SeverityWarning     Severity = 2
+	SeverityCritical    Severity = 3
+	SeverityUnreachable Severity = 4
+	SeverityDown        Severity = 5
+)
+
+// Problem is a host that is not up or a service that is not OK
+type Problem struct {
+	HostName string `json:"host_name"`
+	// ServiceDescription is empty for a host problem
+	ServiceDescription string    `json:"service_description,omitempty"`
+	State              string    `json:"state"`
+	Severity           Severity  `json:"severity"`
+	Since              time.Time `json:"since"`
+	Output             string    `json:"output"`
+	Acknowledged       bool      `json:"acknowledged"`
+	InDowntime         bool      `json:"in_downtime"`
+}
+
+// Key identifies the host or service with the problem
+func (problem *Problem) Key() string {
+	if problem.ServiceDescription == "" {
+		return problem.HostName
+	}
+
+	return problem.HostName + "/" + problem.ServiceDescription
+}
+
+// Handled returns true when someone already knows about the problem, because it is acknowledged or in downtime
+func (problem *Problem) Handled() bool {
+	return problem.Acknowledged || problem.InDowntime
+}
+
+// Snapshot holds the problems found by one poll, sorted, see Sort
+type Snapshot struct {
+	Time     time.Time `json:"time"`
+	Problems []Problem `json:"problems"`
+}
+
+// Collect polls the state of every host and service and returns the current problems
+// Services of hosts that are down are left out, their host already shows the problem
+func Collect(client *gonagios.Client) (*Snapshot, error) {
+	hosts, err := client.ListHostStatus()
+
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := client.ListServiceStatus("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{Time: time.Now(), Problems: []Problem{}}
+	hostsDown := map[string]bool{}
+
+	for i := range hosts {
+		host := &hosts[i]
+
+		if !host.Problem() {
+			continue
+		}
+
+		hostsDown[host.HostName] = true
+
+		severity := SeverityDown
+		if host.CurrentState == gonagios.HostUnreachable {
+			severity = SeverityUnreachable
+		}
+
+		snapshot.Problems = append(snapshot.Problems, newProblem(host.HostName, "", host.CurrentState.String(), severity, &host.CheckStatus))
+	}
+
+	for i := range services {
+		service := &services[i]
+
+		if !service.Problem() || hostsDown[service.HostName] {
+			continue
+		}
+
+		severity := SeverityUnknown
+		switch service.CurrentState {
+		case gonagios.ServiceWarning:
+			severity = SeverityWarning
+		case gonagios.ServiceCritical:
+			severity = SeverityCritical
+		}
+
+		snapshot.Problems = append(snapshot.Problems, newProblem(service.HostName, service.ServiceDescription, service.CurrentState.String(), severity, &service.CheckStatus))
+	}
+
+	Sort(snapshot.Problems)
+
+	return snapshot, nil
+}
+
+// newProblem builds a problem from the status of a host or service
+func newProblem(hostName, serviceDescription, state string, severity Severity, status *gonagios.CheckStatus) Problem {
+	return Problem{
+		HostName:           hostName,
+		ServiceDescription: serviceDescription,
+		State:              state,
+		Severity:           severity,
+		Since:              status.LastStateChange,
+		Output:             status.Output,
+		Acknowledged:       status.ProblemAcknowledged,
+		InDowntime:         status.InDowntime(),
+	}
+}
+
+// Sort orders problems the way they are shown: unhandled problems before handled ones, then the most severe
+// first, then the longest lasting first. Problems that are otherwise equal are ordered by name
+func Sort(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := &problems[i], &problems[j]
+
+		if a.Handled() != b.Handled() {
+			return !a.Handled()
+		}
+
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+
+		if !a.Since.Equal(b.Since) {
+			return a.Since.Before(b.Since)
+		}
+
+		return a.Key() < b.Key()
+	})
+}
+
+// Changes are the differences between two snapshots
+type Changes struct {
+	// New problems were not in the previous snapshot, or were in another state
+	New map[string]bool
+	// Recovered problems were in the previous snapshot but are gone
+	Recovered []Problem
+}
+
+// Compare finds the problems that appeared and recovered between two snapshots
+// Nothing is new when there is no previous snapshot, so the first refresh does not highlight every problem
+func Compare(previous, current *Snapshot) *Changes {
+	changes := &Changes{New: map[string]bool{}}
+
+	if previous == nil {
+		return changes
+	}
+
+	before := map[string]string{}
+	for _, problem := range previous.Problems {
+		before[problem.Key()] = problem.State
+	}
+
+	now := map[string]bool{}
+	for _, problem := range current.Problems {
+		now[problem.Key()] = true
+
+		if state, ok := before[problem.Key()]; !ok || state != problem.State {
+			changes.New[problem.Key()] = true
+		}
+	}
+
+	for _, problem := range previous.Problems {
+		if !now[problem.Key()] {
+			changes.Recovered = append(changes.Recovered, problem)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,91 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/devopsdunkin/gonagios/internal/fakenagios"
+	"github.com/stretchr/testify/assert"
+)
+
+// newProblemServer returns a fake XI with problems of every kind
+func newProblemServer() *fakenagios.Server {
+	server := fakenagios.New()
+
+	server.AddRecord("hoststatus", map[string]string{"host_name": "web01", "current_state": "0"})
+	server.AddRecord("hoststatus", map[string]string{"host_name": "db01", "current_state": "1", "last_state_change": "2019-10-01 10:00:00", "output": "PING CRITICAL"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "HTTP", "current_state": "1", "last_state_change": "2019-10-01 09:00:00"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "Disk", "current_state": "2", "last_state_change": "2019-10-01 11:00:00"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "Load", "current_state": "2", "last_state_change": "2019-10-01 08:00:00", "problem_acknowledged": "1"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "web01", "service_description": "PING", "current_state": "0"})
+	server.AddRecord("servicestatus", map[string]string{"host_name": "db01", "service_description": "MySQL", "current_state": "2"})
+
+	return server
+}
+
+func TestWatch_collect(t *testing.T) {
+	server := newProblemServer()
+	defer server.Close()
+
+	snapshot, err := Collect(gonagios.NewClient(server.URL, "token123"))
+
+	assert.NoError(t, err)
+
+	var keys []string
+	for _, problem := range snapshot.Problems {
+		keys = append(keys, problem.Key())
+	}
+
+	// db01/MySQL is left out because db01 is down
+	assert.Equal(t, []string{"db01", "web01/Disk", "web01/HTTP", "web01/Load"}, keys)
+	assert.Equal(t, SeverityDown, snapshot.Problems[0].Severity)
+	assert.Equal(t, "DOWN", snapshot.Problems[0].State)
+	assert.Equal(t, "PING CRITICAL", snapshot.Problems[0].Output)
+	assert.True(t, snapshot.Problems[3].Handled())
+}
+
+func TestWatch_sort(t *testing.T) {
+	early := time.Date(2019, 10, 1, 8, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	problems := []Problem{
+		{HostName: "a", ServiceDescription: "warning", Severity: SeverityWarning, Since: early},
+		{HostName: "a", ServiceDescription: "late", Severity: SeverityCritical, Since: late},
+		{HostName: "a", ServiceDescription: "acked", Severity: SeverityCritical, Since: early, Acknowledged: true},
+		{HostName: "a", ServiceDescription: "early", Severity: SeverityCritical, Since: early},
+		{HostName: "b", Severity: SeverityUnreachable, Since: late},
+	}
+
+	Sort(problems)
+
+	var keys []string
+	for _, problem := range problems {
+		keys = append(keys, problem.Key())
+	}
+
+	assert.Equal(t, []string{"b", "a/early", "a/late", "a/warning", "a/acked"}, keys)
+}
+
+func TestWatch_compare(t *testing.T) {
+	previous := &Snapshot{Problems: []Problem{
+		{HostName: "web01", ServiceDescription: "HTTP", State: "WARNING"},
+		{HostName: "web01", ServiceDescription: "Disk", State: "CRITICAL"},
+		{HostName: "db01", State: "DOWN"},
+	}}
+	current := &Snapshot{Problems: []Problem{
+		{HostName: "web01", ServiceDescription: "HTTP", State: "CRITICAL"},
+		{HostName: "web01", ServiceDescription: "Disk", State: "CRITICAL"},
+		{HostName: "web02", State: "DOWN"},
+	}}
+
+	changes := Compare(previous, current)
+
+	assert.Equal(t, map[string]bool{"web01/HTTP": true, "web02": true}, changes.New)
+	assert.Equal(t, []Problem{{HostName: "db01", State: "DOWN"}}, changes.Recovered)
+
+	changes = Compare(nil, current)
+
+	assert.Empty(t, changes.New)
+	assert.Empty(t, changes.Recovered)
+}
@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+)
+
+// DefaultInterval is how often a Watcher polls when Interval is not set
+const DefaultInterval = 30 * time.Second
+
+// Watcher polls XI at an interval and redraws the problems after every poll
+type Watcher struct {
+	Client   *gonagios.Client
+	Interval time.Duration
+	Output   io.Writer
+	// Options control how each refresh is drawn. Options.Err is set by the watcher
+	Options RenderOptions
+
+	previous *Snapshot
+}
+
+// Run polls and redraws until ctx is done, and then returns nil
+// A failed poll does not stop the watcher: the last problems stay on screen with the error above them
+func (watcher *Watcher) Run(ctx context.Context) error {
+	if watcher.Client == nil || watcher.Output == nil {
+		return errors.New("a watcher needs a client and an output")
+	}
+
+	interval := watcher.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := watcher.Refresh(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh polls once and redraws. Only an error writing to the output is returned
+func (watcher *Watcher) Refresh() error {
+	options := watcher.Options
+
+	snapshot, err := Collect(watcher.Client)
+
+	if err != nil {
+		options.Err = err
+
+		snapshot = watcher.previous
+		if snapshot == nil {
+			snapshot = &Snapshot{Time: time.Now(), Problems: []Problem{}}
+		}
+
+		return Render(watcher.Output, snapshot, &Changes{New: map[string]bool{}}, options)
+	}
+
+	changes := Compare(watcher.previous, snapshot)
+	watcher.previous = snapshot
+
+	return Render(watcher.Output, snapshot, changes, options)
+}
@@ -0,0 +1,72 @@
+package watch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devopsdunkin/gonagios"
+	"github.com/stretchr/testify/assert"
+)
+
+// refreshes records every refresh a watcher draws and calls drawn after each of them
+type refreshes struct {
+	views []string
+	drawn func(count int)
+}
+
+func (recorder *refreshes) Write(data []byte) (int, error) {
+	recorder.views = append(recorder.views, string(data))
+	recorder.drawn(len(recorder.views))
+
+	return len(data), nil
+}
+
+func TestWatcher_run(t *testing.T) {
+	server := newProblemServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A new problem appears after the first refresh, and the watcher stops after the second
+	recorder := &refreshes{drawn: func(count int) {
+		if count == 1 {
+			server.AddRecord("hoststatus", map[string]string{"host_name": "web02", "current_state": "2"})
+		} else {
+			cancel()
+		}
+	}}
+
+	client := gonagios.NewClient(server.URL, "token123")
+	watcher := &Watcher{Client: client, Interval: 10 * time.Millisecond, Output: recorder}
+
+	assert.NoError(t, watcher.Run(ctx))
+	assert.Len(t, recorder.views, 2)
+	assert.Contains(t, recorder.views[0], "4 problems, 0 new, 0 recovered")
+	assert.Contains(t, recorder.views[1], "5 problems, 1 new, 0 recovered")
+	assert.Contains(t, recorder.views[1], "+  UNREACHABLE")
+}
+
+func TestWatcher_pollFailure(t *testing.T) {
+	server := newProblemServer()
+
+	var output strings.Builder
+
+	client := gonagios.NewClient(server.URL, "token123")
+	client.RetryPolicy.MaxAttempts = 1
+
+	watcher := &Watcher{Client: client, Output: &output}
+
+	assert.NoError(t, watcher.Refresh())
+
+	server.Close()
+	output.Reset()
+
+	// The last problems stay on screen with the error
+	assert.NoError(t, watcher.Refresh())
+	assert.Contains(t, output.String(), "poll failed")
+	assert.Contains(t, output.String(), "4 problems")
+	assert.NotContains(t, output.String(), "token123")
+}